@@ -33,6 +33,7 @@ func main() {
 			ApplicationFactory:    libbs.NewApplicationFactory(),
 			Logger:                bard.NewLogger(os.Stdout),
 			HomeDirectoryResolver: gradle.OSHomeDirectoryResolver{},
+			ResourcesResolver:     gradle.ContainerResources{Root: "/"},
 		},
 	)
 }
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak/bindings"
+)
+
+// GradleBindings returns the bindings of type gradle in the order they are applied: by ascending priority secret
+// (defaulting to 0), then by name. Later bindings take precedence over earlier ones.
+func GradleBindings(platformBindings libcnb.Bindings) (libcnb.Bindings, error) {
+	gradleBindings := bindings.Resolve(platformBindings, bindings.OfType("gradle"))
+	priorities := map[string]int{}
+
+	for _, binding := range gradleBindings {
+		if s, ok := binding.Secret["priority"]; ok {
+			priority, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil {
+				return nil, fmt.Errorf("invalid priority %s in binding %s\n%w", strings.TrimSpace(s), binding.Name, err)
+			}
+			priorities[binding.Name] = priority
+		}
+	}
+
+	sort.SliceStable(gradleBindings, func(i, j int) bool {
+		a, b := gradleBindings[i], gradleBindings[j]
+		if priorities[a.Name] != priorities[b.Name] {
+			return priorities[a.Name] < priorities[b.Name]
+		}
+		return a.Name < b.Name
+	})
+
+	return gradleBindings, nil
+}
+
+// BoundInitScripts returns the paths of the *.gradle and *.gradle.kts secrets of bindings by file name. Later bindings
+// take precedence over earlier ones for scripts of the same name.
+func BoundInitScripts(bindings libcnb.Bindings) map[string]string {
+	scripts := map[string]string{}
+
+	for _, binding := range bindings {
+		for name := range binding.Secret {
+			if !strings.HasSuffix(name, ".gradle") && !strings.HasSuffix(name, ".gradle.kts") {
+				continue
+			}
+
+			if path, ok := binding.SecretFilePath(name); ok {
+				scripts[name] = path
+			}
+		}
+	}
+
+	return scripts
+}
+
+// BindingCredentials returns the trimmed username and password (or token) secrets of binding and false if it has
+// neither.
+func BindingCredentials(binding libcnb.Binding) (string, string, bool, error) {
+	username, hasUsername := binding.Secret["username"]
+	password, hasPassword := binding.Secret["password"]
+	if !hasPassword {
+		password, hasPassword = binding.Secret["token"]
+	}
+
+	if !hasUsername && !hasPassword {
+		return "", "", false, nil
+	} else if !hasUsername {
+		return "", "", false, fmt.Errorf("binding %s requires a username secret to authenticate with a password or token", binding.Name)
+	} else if !hasPassword {
+		return "", "", false, fmt.Errorf("binding %s requires a password or token secret to authenticate with a username", binding.Name)
+	}
+
+	return strings.TrimSpace(username), strings.TrimSpace(password), true, nil
+}
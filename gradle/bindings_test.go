@@ -0,0 +1,91 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle_test
+
+import (
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/gradle/v7/gradle"
+)
+
+func testBindings(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	it("returns only gradle bindings", func() {
+		Expect(gradle.GradleBindings(libcnb.Bindings{
+			{Name: "some-gradle", Type: "gradle"},
+			{Name: "some-wrapper", Type: "gradle-wrapper"},
+		})).To(Equal(libcnb.Bindings{{Name: "some-gradle", Type: "gradle"}}))
+	})
+
+	it("orders bindings by name", func() {
+		bindings, err := gradle.GradleBindings(libcnb.Bindings{
+			{Name: "b-gradle", Type: "gradle"},
+			{Name: "a-gradle", Type: "Gradle"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(bindings).To(HaveLen(2))
+		Expect(bindings[0].Name).To(Equal("a-gradle"))
+		Expect(bindings[1].Name).To(Equal("b-gradle"))
+	})
+
+	it("orders bindings by priority before name", func() {
+		bindings, err := gradle.GradleBindings(libcnb.Bindings{
+			{Name: "a-gradle", Type: "gradle", Secret: map[string]string{"priority": "10\n"}},
+			{Name: "b-gradle", Type: "gradle"},
+			{Name: "c-gradle", Type: "gradle", Secret: map[string]string{"priority": "-1"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(bindings).To(HaveLen(3))
+		Expect(bindings[0].Name).To(Equal("c-gradle"))
+		Expect(bindings[1].Name).To(Equal("b-gradle"))
+		Expect(bindings[2].Name).To(Equal("a-gradle"))
+	})
+
+	it("fails with an invalid priority", func() {
+		_, err := gradle.GradleBindings(libcnb.Bindings{
+			{Name: "some-gradle", Type: "gradle", Secret: map[string]string{"priority": "high"}},
+		})
+		Expect(err).To(MatchError(ContainSubstring("invalid priority high in binding some-gradle")))
+	})
+
+	it("returns bound init scripts by name", func() {
+		Expect(gradle.BoundInitScripts(libcnb.Bindings{
+			{
+				Name:   "a-gradle",
+				Path:   "/bindings/a-gradle",
+				Secret: map[string]string{"gradle.properties": "", "repositories.gradle": "", "shared.gradle.kts": ""},
+			},
+			{
+				Name:   "b-gradle",
+				Path:   "/bindings/b-gradle",
+				Secret: map[string]string{"shared.gradle.kts": "", "README": ""},
+			},
+		})).To(Equal(map[string]string{
+			"repositories.gradle": "/bindings/a-gradle/repositories.gradle",
+			"shared.gradle.kts":   "/bindings/b-gradle/shared.gradle.kts",
+		}))
+	})
+}
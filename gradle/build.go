@@ -24,9 +24,15 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/paketo-buildpacks/libpak/effect"
 	"github.com/paketo-buildpacks/libpak/sbom"
+	"github.com/paketo-buildpacks/libpak/sherpa"
 
 	"github.com/paketo-buildpacks/libpak/bindings"
 
@@ -36,10 +42,14 @@ import (
 	"github.com/paketo-buildpacks/libpak/bard"
 )
 
+// BuildFlags are passed to Gradle before the tasks unless BP_GRADLE_BUILD_ARGUMENTS replaces all arguments.
+var BuildFlags = []string{"--no-daemon", "-Dorg.gradle.welcome=never"}
+
 type Build struct {
 	Logger                bard.Logger
 	ApplicationFactory    ApplicationFactory
 	HomeDirectoryResolver HomeDirectoryResolver
+	ResourcesResolver     ResourcesResolver
 }
 
 type ApplicationFactory interface {
@@ -53,6 +63,12 @@ type HomeDirectoryResolver interface {
 
 type OSHomeDirectoryResolver struct{}
 
+// ResourcesResolver resolves the memory in bytes, 0 if unknown, and the number of CPUs available to the build.
+type ResourcesResolver interface {
+	Memory() (int64, error)
+	CPUs() (int, error)
+}
+
 func (p OSHomeDirectoryResolver) Location() (string, error) {
 	u, err := user.Current()
 	if err != nil {
@@ -82,39 +98,149 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 	}
 	dc.Logger = b.Logger
 
-	command := filepath.Join(context.Application.Path, "gradlew")
+	projectPath, err := ProjectPath(cr)
+	if err != nil {
+		return libcnb.BuildResult{}, err
+	}
+	projectDir := filepath.Join(context.Application.Path, projectPath)
+	if projectPath != "." {
+		if ok, err := sherpa.DirExists(projectDir); err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to check for %s\n%w", projectDir, err)
+		} else if !ok {
+			return libcnb.BuildResult{}, fmt.Errorf("BP_GRADLE_PROJECT_PATH %s is not a directory in the application", projectPath)
+		}
+		b.Logger.Bodyf("Building Gradle project in %s", projectPath)
+	}
+
+	useWrapper := true
+	if s, _ := cr.Resolve("BP_GRADLE_USE_WRAPPER"); s != "" {
+		useWrapper, err = strconv.ParseBool(s)
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to parse BP_GRADLE_USE_WRAPPER value %s\n%w", s, err)
+		}
+	}
+
+	homeDir, err := b.HomeDirectoryResolver.Location()
+	if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve home directory\n%w", err)
+	}
+	gradleHome := filepath.Join(homeDir, ".gradle")
+
+	wrapperBinding, wrapperBindingExists, err := bindings.ResolveOne(context.Platform.Bindings, bindings.OfType("gradle-wrapper"))
+	if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve binding\n%w", err)
+	}
+
+	gradleWrapperHome := filepath.Join(projectDir, "gradle", "wrapper")
+	wrapperPropertiesPaths := []string{filepath.Join(gradleWrapperHome, "gradle-wrapper.properties")}
+	if path, ok := wrapperBinding.SecretFilePath("gradle-wrapper.properties"); ok {
+		wrapperPropertiesPaths = append(wrapperPropertiesPaths, path)
+	}
+	wp, wrapperPropertiesExist, err := NewWrapperProperties(wrapperPropertiesPaths...)
+	if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to read wrapper properties\n%w", err)
+	}
+
+	command := filepath.Join(projectDir, "gradlew")
+	wrapperExists := true
 	if _, err := os.Stat(command); os.IsNotExist(err) {
-		dep, err := dr.Resolve("gradle", "")
+		wrapperExists = false
+	} else if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to stat %s\n%w", command, err)
+	} else if !useWrapper {
+		b.Logger.Bodyf("Ignoring %s as BP_GRADLE_USE_WRAPPER is false", command)
+	}
+
+	var wrapperDistribution *WrapperDistribution
+	var gradleVersion string
+	environment := map[string]string{}
+	files := map[string]string{}
+	var javaOpts []string
+	var wrapperCredentials map[string]string
+	if !wrapperExists || !useWrapper {
+		compatible := false
+		version, _ := cr.Resolve("BP_GRADLE_VERSION")
+		if version != "" {
+			b.Logger.Bodyf("Gradle %s requested by BP_GRADLE_VERSION", version)
+		} else if wrapperPropertiesExist {
+			if version, err = wp.Version(); err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to determine Gradle version from %s\n%w", strings.Join(wrapperPropertiesPaths, ", "), err)
+			}
+			compatible = true
+			b.Logger.Bodyf("Gradle %s requested by %s", version, strings.Join(wrapperPropertiesPaths, ", "))
+		}
+
+		dep, err := resolveDistribution(dr, version, compatible)
 		if err != nil {
 			return libcnb.BuildResult{}, fmt.Errorf("unable to find dependency\n%w", err)
 		}
 
-		d, be := NewDistribution(dep, dc)
+		d, be := NewDistribution(dep, version, dc)
 		d.Logger = b.Logger
+		b.Logger.Bodyf("Using Gradle %s from %s", dep.Version, dep.URI)
+		gradleVersion = dep.Version
 		result.Layers = append(result.Layers, d)
 		result.BOM.Entries = append(result.BOM.Entries, be)
 		command = filepath.Join(context.Layers.Path, d.Name(), "bin", "gradle")
-	} else if err != nil {
-		return libcnb.BuildResult{}, fmt.Errorf("unable to stat %s\n%w", command, err)
 	} else {
+		validationMode, _ := cr.Resolve("BP_GRADLE_WRAPPER_VALIDATION")
+		if validationMode == "" {
+			validationMode = WrapperValidationWarn
+		}
+		validator, err := NewWrapperValidator(validationMode, context.Buildpack.Metadata, wrapperBinding, b.Logger)
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to create wrapper validator\n%w", err)
+		}
+		if err := validator.Validate(filepath.Join(gradleWrapperHome, "gradle-wrapper.jar")); err != nil {
+			return libcnb.BuildResult{}, err
+		}
+
 		if err := os.Chmod(command, 0755); err != nil {
 			b.Logger.Bodyf("WARNING: unable to chmod %s:\n%s", command, err)
 		}
-	}
 
-	homeDir, err := b.HomeDirectoryResolver.Location()
-	if err != nil {
-		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve home directory\n%w", err)
+		if wrapperCredentials, err = WrapperCredentials(wrapperBinding); err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to resolve wrapper credentials\n%w", err)
+		} else if len(wrapperCredentials) > 0 {
+			b.Logger.Bodyf("Configuring wrapper credentials from binding %s", wrapperBinding.Name)
+		}
+
+		if wrapperPropertiesExist {
+			gradleVersion, _ = wp.Version()
+			wrapperDistribution, err = b.wrapperDistribution(wp, dr, dc, gradleHome, projectDir)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to resolve wrapper distribution\n%w", err)
+			}
+		}
 	}
-	gradleHome := filepath.Join(homeDir, ".gradle")
 
 	c := libbs.Cache{Path: gradleHome}
 	c.Logger = b.Logger
-	result.Layers = append(result.Layers, c)
 
-	args, err := libbs.ResolveArguments("BP_GRADLE_BUILD_ARGUMENTS", cr)
+	strategy, _ := cr.Resolve("BP_GRADLE_CACHE_STRATEGY")
+	if strategy == "" {
+		strategy = CacheStrategyPersistent
+	} else if strategy != CacheStrategyPersistent && strategy != CacheStrategyFingerprint {
+		return libcnb.BuildResult{}, fmt.Errorf("invalid BP_GRADLE_CACHE_STRATEGY %s, must be one of %s or %s",
+			strategy, CacheStrategyPersistent, CacheStrategyFingerprint)
+	}
+	fingerprint, err := DependencyFingerprint(projectDir)
 	if err != nil {
-		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve build arguments\n%w", err)
+		return libcnb.BuildResult{}, fmt.Errorf("unable to fingerprint dependencies\n%w", err)
+	}
+	result.Layers = append(result.Layers, Cache{Delegate: c, Fingerprint: fingerprint, Logger: b.Logger, Strategy: strategy})
+
+	if wrapperDistribution != nil {
+		result.Layers = append(result.Layers, *wrapperDistribution)
+	}
+
+	var args []string
+	if _, ok := cr.Resolve("BP_GRADLE_BUILD_ARGUMENTS"); ok {
+		if args, err = libbs.ResolveArguments("BP_GRADLE_BUILD_ARGUMENTS", cr); err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to resolve build arguments\n%w", err)
+		}
+	} else if args, err = taskArguments(cr); err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve build tasks\n%w", err)
 	}
 
 	additionalArgs, err := libbs.ResolveArguments("BP_GRADLE_ADDITIONAL_BUILD_ARGUMENTS", cr)
@@ -124,45 +250,305 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 		args = append(args, additionalArgs...)
 	}
 
-	initScriptPath, _ := cr.Resolve("BP_GRADLE_INIT_SCRIPT_PATH")
-	if initScriptPath != "" {
-		args = append([]string{"--init-script", initScriptPath}, args...)
+	runTests := cr.ResolveBool("BP_GRADLE_RUN_TESTS")
+	if runTests && !slices.Contains(args, "test") {
+		args = append(args, "test")
 	}
 
 	md := map[string]interface{}{}
-	if binding, ok, err := bindings.ResolveOne(context.Platform.Bindings, bindings.OfType("gradle")); err != nil {
-		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve binding\n%w", err)
-	} else if ok {
-		b.Logger.Debug("binding of type gradle successfully detected, configuring layer")
-		gradlePropertiesPath, ok := binding.SecretFilePath("gradle.properties")
-		if ok {
+	if projectPath != "." {
+		md["gradle-project-path"] = projectPath
+	}
+
+	initScriptPaths, _ := cr.Resolve("BP_GRADLE_INIT_SCRIPT_PATH")
+	var initScripts []string
+	for _, path := range filepath.SplitList(initScriptPaths) {
+		if path != "" {
+			initScripts = append(initScripts, path)
+		}
+	}
+
+	if script, _ := cr.Resolve("BP_GRADLE_INIT_SCRIPT"); strings.TrimSpace(script) != "" {
+		// named after its content so that the arguments recorded in the layer metadata are stable between builds
+		sum := sha256.Sum256([]byte(script))
+		path := filepath.Join(os.TempDir(), fmt.Sprintf("gradle-init-%s.gradle", hex.EncodeToString(sum[:])[:16]))
+		if err := os.WriteFile(path, []byte(script), 0600); err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to write init script %s\n%w", path, err)
+		}
+		initScripts = append(initScripts, path)
+	}
+
+	if len(initScripts) > 0 {
+		var initArgs []string
+		hashes := map[string]interface{}{}
+		for _, path := range initScripts {
+			initArgs = append(initArgs, "--init-script", path)
+
+			file := path
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(projectDir, file)
+			}
+			if hash, ok, err := fileSha256(file); err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to hash init script %s\n%w", path, err)
+			} else if ok {
+				hashes[path] = hash
+			} else {
+				b.Logger.Debugf("init script %s does not exist yet, not adding it to the layer metadata", path)
+			}
+		}
+		args = append(initArgs, args...)
+		md["gradle-init-script-sha256"] = hashes
+	}
+
+	gradleProperties := PropertiesFile{
+		Defaults:                 map[string]string{},
+		GradlePropertiesHome:     gradleHome,
+		GradlePropertiesFileName: "gradle.properties",
+		GradlePropertiesName:     "gradle-properties",
+		Logger:                   b.Logger,
+		ProjectGradleProperties:  filepath.Join(projectDir, "gradle.properties"),
+	}
+	proxy, err := ProxyProperties(os.Environ())
+	if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve proxy configuration\n%w", err)
+	}
+	for k, v := range proxy {
+		gradleProperties.Defaults[k] = v
+	}
+	// the wrapper reads the credentials from the merged gradle.properties, which is only linked into $GRADLE_USER_HOME
+	// while Gradle runs
+	for k, v := range wrapperCredentials {
+		gradleProperties.Defaults[k] = v
+	}
+
+	if gradleProperties.JVMArgOverrides, err = libbs.ResolveArguments("BP_GRADLE_JVM_ARGS", cr); err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve JVM arguments\n%w", err)
+	}
+
+	if caBindings := bindings.Resolve(context.Platform.Bindings, bindings.OfType("ca-certificates")); len(caBindings) > 0 {
+		b.Logger.Debugf("%d binding(s) of type ca-certificates successfully detected, configuring truststore", len(caBindings))
+		truststore := Truststore{Bindings: caBindings, JavaHome: os.Getenv("JAVA_HOME"), Logger: b.Logger}
+		result.Layers = append(result.Layers, truststore)
+
+		// the wrapper and the Gradle client read JAVA_OPTS, the JVM running the build org.gradle.jvmargs
+		gradleProperties.JVMArgs = append(gradleProperties.JVMArgs, truststore.SystemProperties(context.Layers.Path)...)
+		javaOpts = append(javaOpts, truststore.SystemProperties(context.Layers.Path)...)
+	}
+
+	gradleBindings, err := GradleBindings(context.Platform.Bindings)
+	if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve bindings\n%w", err)
+	}
+	if len(gradleBindings) > 0 {
+		b.Logger.Debugf("%d binding(s) of type gradle successfully detected, configuring layer", len(gradleBindings))
+
+		// hash of the bound gradle.properties files in the order they are applied
+		hasher := sha256.New()
+		for _, binding := range gradleBindings {
+			gradlePropertiesPath, ok := binding.SecretFilePath("gradle.properties")
+			if !ok {
+				continue
+			}
+
 			gradlePropertiesFile, err := os.Open(gradlePropertiesPath)
 			if err != nil {
 				return libcnb.BuildResult{}, fmt.Errorf("unable to open gradle.properties\n%w", err)
 			}
 
-			hasher := sha256.New()
 			if _, err := io.Copy(hasher, gradlePropertiesFile); err != nil {
+				gradlePropertiesFile.Close()
 				return libcnb.BuildResult{}, fmt.Errorf("unable to hash gradle.properties\n%w", err)
 			}
+			gradlePropertiesFile.Close()
+
 			md["gradle-properties-sha256"] = hex.EncodeToString(hasher.Sum(nil))
+			gradleProperties.Bindings = append(gradleProperties.Bindings, binding)
+		}
 
-			result.Layers = append(result.Layers, PropertiesFile{
-				binding,
-				gradleHome,
-				"gradle.properties",
-				"gradle-properties",
-				b.Logger,
-			})
+		// init scripts are linked into $GRADLE_USER_HOME/init.d only while Gradle runs, like gradle.properties
+		if scripts := BoundInitScripts(gradleBindings); len(scripts) > 0 {
+			hasher := sha256.New()
+			for _, name := range sortedKeys(scripts) {
+				b.Logger.Bodyf("Installing init script %s", name)
+
+				script, err := os.Open(scripts[name])
+				if err != nil {
+					return libcnb.BuildResult{}, fmt.Errorf("unable to open %s\n%w", scripts[name], err)
+				}
+
+				fmt.Fprintf(hasher, "%s\n", name)
+				if _, err := io.Copy(hasher, script); err != nil {
+					script.Close()
+					return libcnb.BuildResult{}, fmt.Errorf("unable to hash %s\n%w", scripts[name], err)
+				}
+				script.Close()
+
+				files[filepath.Join(gradleHome, "init.d", name)] = scripts[name]
+			}
+			md["gradle-init-scripts-sha256"] = hex.EncodeToString(hasher.Sum(nil))
 		}
 	}
 
-	gradleWrapperHome := filepath.Join("gradle", "wrapper")
-	if binding, ok, err := bindings.ResolveOne(context.Platform.Bindings, bindings.OfType("gradle-wrapper")); err != nil {
-		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve binding\n%w", err)
+	gradleProperties.Overrides, err = PropertyOverrides(os.Environ())
+	if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve gradle properties\n%w", err)
+	}
+	if len(gradleProperties.Overrides) > 0 {
+		// the values may be secrets, so only their hash is recorded
+		hasher := sha256.New()
+		for _, k := range sortedKeys(gradleProperties.Overrides) {
+			fmt.Fprintf(hasher, "%s=%s\n", k, gradleProperties.Overrides[k])
+		}
+		md["gradle-property-overrides-sha256"] = hex.EncodeToString(hasher.Sum(nil))
+	}
+
+	jdks, err := InstalledJDKs(os.Getenv("JAVA_HOME"), filepath.Dir(context.Layers.Path))
+	if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to find installed JDKs\n%w", err)
+	}
+	if len(jdks) > 0 {
+		var homes []string
+		for _, jdk := range jdks {
+			homes = append(homes, jdk.Home)
+		}
+		b.Logger.Bodyf("Providing JDK(s) %s to Gradle toolchains", strings.Join(homes, ", "))
+		gradleProperties.Defaults["org.gradle.java.installations.paths"] = strings.Join(homes, ",")
+		gradleProperties.Defaults["org.gradle.java.installations.auto-download"] = "false"
+
+		paths := []string{gradleProperties.ProjectGradleProperties, filepath.Join(gradleHome, "gradle.properties")}
+		for _, binding := range gradleProperties.Bindings {
+			if path, ok := binding.SecretFilePath("gradle.properties"); ok {
+				paths = append(paths, path)
+			}
+		}
+		autoDownload, err := ToolchainAutoDownload(gradleProperties.Overrides, paths...)
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to resolve toolchain auto-download\n%w", err)
+		}
+
+		if !autoDownload {
+			requested, err := RequestedToolchains(projectDir)
+			if err != nil {
+				return libcnb.BuildResult{}, err
+			}
+			if err := VerifyToolchains(requested, jdks); err != nil {
+				return libcnb.BuildResult{}, err
+			}
+		}
+	}
+
+	if b.ResourcesResolver != nil {
+		memory, err := b.ResourcesResolver.Memory()
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to determine available memory\n%w", err)
+		}
+		cpus, err := b.ResourcesResolver.CPUs()
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to determine available CPUs\n%w", err)
+		}
+
+		if memory > 0 {
+			b.Logger.Bodyf("Sizing Gradle for %dM of memory and %d CPU(s)", memory/MiB, cpus)
+			gradleProperties.JVMArgs = append(gradleProperties.JVMArgs, DaemonJVMArgs(memory)...)
+		}
+		gradleProperties.Defaults["org.gradle.workers.max"] = strconv.Itoa(Workers(memory, cpus))
+	}
+
+	if len(gradleProperties.Bindings) > 0 || len(gradleProperties.Defaults) > 0 || len(gradleProperties.JVMArgs) > 0 ||
+		len(gradleProperties.JVMArgOverrides) > 0 || len(gradleProperties.Overrides) > 0 {
+		files[filepath.Join(gradleHome, "gradle.properties")] = filepath.Join(context.Layers.Path, gradleProperties.Name(), "gradle.properties")
+		result.Layers = append(result.Layers, gradleProperties)
+	}
+
+	var generatedInitScripts []InitScript
+
+	if mirror, ok, err := NewRepositoryMirror(cr, context.Platform.Bindings); err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve repository mirror\n%w", err)
 	} else if ok {
+		if mirror.Mirror != "" {
+			b.Logger.Bodyf("Mirroring repositories to %s", mirror.Mirror)
+			md["gradle-repository-mirror"] = mirror.Mirror
+		}
+		if mirror.PluginMirror != "" {
+			b.Logger.Bodyf("Mirroring plugin repositories to %s", mirror.PluginMirror)
+			md["gradle-plugin-repository-mirror"] = mirror.PluginMirror
+		}
+		for k, v := range mirror.Environment() {
+			environment[k] = v
+		}
+		generatedInitScripts = append(generatedInitScripts, InitScript{Content: RepositoryMirrorScript, FileName: "repository-mirror.gradle", Logger: b.Logger})
+	}
+
+	if allowlist, ok, err := NewRepositoryAllowlist(cr); err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve repository allowlist\n%w", err)
+	} else if ok {
+		b.Logger.Bodyf("Allowing repositories of %s only", strings.Join(allowlist.Entries, ", "))
+		for k, v := range allowlist.Environment() {
+			environment[k] = v
+		}
+		md["gradle-allowed-repositories"] = allowlist.Entries
+		generatedInitScripts = append(generatedInitScripts, InitScript{Content: RepositoryAllowlistScript, FileName: "repository-allowlist.gradle", Logger: b.Logger})
+	}
+
+	if cache, ok, err := NewBuildCache(context.Platform.Bindings); err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve remote build cache\n%w", err)
+	} else if ok {
+		if cache.Push {
+			b.Logger.Bodyf("Using remote build cache %s with push enabled", cache.RedactedURL())
+		} else {
+			b.Logger.Bodyf("Using remote build cache %s", cache.RedactedURL())
+		}
+		for k, v := range cache.Environment() {
+			environment[k] = v
+		}
+		md["gradle-build-cache"] = cache.RedactedURL()
+		generatedInitScripts = append(generatedInitScripts, InitScript{Content: BuildCacheScript, FileName: "build-cache.gradle", Logger: b.Logger})
+
+		if !slices.Contains(args, "--build-cache") && !slices.Contains(args, "--no-build-cache") {
+			args = append(args, "--build-cache")
+		}
+	}
+
+	for _, script := range generatedInitScripts {
+		files[filepath.Join(gradleHome, "init.d", script.FileName)] = script.Path(context.Layers.Path)
+		result.Layers = append(result.Layers, script)
+	}
+
+	pruner := CachePruner{GradleHome: gradleHome, Logger: b.Logger, Version: gradleVersion}
+	if s, _ := cr.Resolve("BP_GRADLE_CACHE_RETENTION_DAYS"); s != "" {
+		days, err := strconv.Atoi(s)
+		if err != nil || days < 0 {
+			return libcnb.BuildResult{}, fmt.Errorf("invalid BP_GRADLE_CACHE_RETENTION_DAYS %s, expected a number of days", s)
+		}
+		pruner.Retention = time.Duration(days) * 24 * time.Hour
+	}
+	if s, _ := cr.Resolve("BP_GRADLE_CACHE_MAX_SIZE"); s != "" {
+		if pruner.MaxSize, err = ParseSize(s); err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to parse BP_GRADLE_CACHE_MAX_SIZE\n%w", err)
+		}
+	}
+
+	var after []func() error
+	if runTests {
+		reports := TestReports{ApplicationPath: context.Application.Path, Logger: b.Logger}
+		result.Layers = append(result.Layers, reports)
+		after = append(after, func() error { return reports.Export(context.Layers.Path) })
+	}
+	// pruning is housekeeping that must not fail a build that succeeded
+	after = append(after, func() error {
+		if err := pruner.Prune(); err != nil {
+			b.Logger.Bodyf("WARNING: unable to prune %s:\n%s", gradleHome, err)
+		}
+		return nil
+	})
+
+	if len(javaOpts) > 0 {
+		environment["JAVA_OPTS"] = sherpa.AppendToEnvVar("JAVA_OPTS", " ", javaOpts...)
+	}
+
+	if wrapperBindingExists {
 		b.Logger.Debug("binding of type gradle-wrapper successfully detected, configuring layer")
-		gradleWrapperPropertiesPath, ok := binding.SecretFilePath("gradle-wrapper.properties")
+		gradleWrapperPropertiesPath, ok := wrapperBinding.SecretFilePath("gradle-wrapper.properties")
 		if ok {
 			gradleWrapperPropertiesFile, err := os.Open(gradleWrapperPropertiesPath)
 			if err != nil {
@@ -176,11 +562,11 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 			md["gradle-wrapper-properties-sha256"] = hex.EncodeToString(hasher.Sum(nil))
 
 			result.Layers = append(result.Layers, PropertiesFile{
-				binding,
-				gradleWrapperHome,
-				"gradle-wrapper.properties",
-				"gradle-wrapper-properties",
-				b.Logger,
+				Bindings:                 libcnb.Bindings{wrapperBinding},
+				GradlePropertiesHome:     gradleWrapperHome,
+				GradlePropertiesFileName: "gradle-wrapper.properties",
+				GradlePropertiesName:     "gradle-wrapper-properties",
+				Logger:                   b.Logger,
 			})
 		}
 	}
@@ -192,6 +578,10 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 		InterestingFileDetector:  libbs.JARInterestingFileDetector{},
 		AdditionalHelpMessage:    "If this is unexpected, please try setting `rootProject.name` in `settings.gradle` or add a project.toml file and exclude the `build/` directory. For details see https://buildpacks.io/docs/app-developer-guide/using-project-descriptor/.",
 	}
+	if projectPath != "." {
+		art.ConfigurationResolver = ProjectArtifactResolver(cr, projectPath)
+		art.ModuleConfigurationKey = ""
+	}
 
 	bomScanner := sbom.NewSyftCLISBOMScanner(context.Layers, effect.CommandExecutor{}, b.Logger)
 	a, err := b.ApplicationFactory.NewApplication(
@@ -208,7 +598,137 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 		return libcnb.BuildResult{}, fmt.Errorf("unable to create application layer\n%w", err)
 	}
 	a.Logger = b.Logger
+	a.Executor = Executor{
+		Delegate:    a.Executor,
+		Dir:         projectDir,
+		Environment: environment,
+		Files:       files,
+		After:       after,
+	}
 	result.Layers = append(result.Layers, a)
 
 	return result, nil
 }
+
+// wrapperDistribution returns a WrapperDistribution if the buildpack provides exactly the distribution requested by the
+// wrapper properties, or nil if the wrapper has to download it itself.
+func (b Build) wrapperDistribution(wp WrapperProperties, dr libpak.DependencyResolver, dc libpak.DependencyCache, gradleHome string, projectPath string) (*WrapperDistribution, error) {
+	version, err := wp.Version()
+	if err != nil {
+		b.Logger.Debugf("Not providing wrapper distribution: %s", err)
+		return nil, nil
+	}
+
+	if t, _ := wp.Type(); t != "bin" {
+		b.Logger.Debugf("Not providing wrapper distribution: %s distributions are not available", t)
+		return nil, nil
+	}
+
+	dep, err := dr.Resolve("gradle", version)
+	if libpak.IsNoValidDependencies(err) {
+		b.Logger.Debugf("Not providing wrapper distribution: Gradle %s is not available", version)
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	requested, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse version %s\n%w", version, err)
+	}
+	available, err := semver.NewVersion(dep.Version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse version %s\n%w", dep.Version, err)
+	}
+	if !requested.Equal(available) {
+		b.Logger.Debugf("Not providing wrapper distribution: Gradle %s is not available", version)
+		return nil, nil
+	}
+
+	if wp.DistributionSHA256Sum != "" && wp.DistributionSHA256Sum != dep.SHA256 {
+		b.Logger.Bodyf("Not providing wrapper distribution: distributionSha256Sum %s does not match %s", wp.DistributionSHA256Sum, dep.SHA256)
+		return nil, nil
+	}
+
+	dir, err := wp.DistributionDirectory(gradleHome, projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine wrapper distribution directory\n%w", err)
+	}
+
+	marker, err := wp.MarkerFile(gradleHome, projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine wrapper marker file\n%w", err)
+	}
+
+	return &WrapperDistribution{
+		Dependency:            dep,
+		DependencyCache:       dc,
+		DistributionDirectory: dir,
+		MarkerFile:            marker,
+		Logger:                b.Logger,
+	}, nil
+}
+
+// resolveDistribution resolves the newest Gradle dependency matching version. If compatible is set and nothing matches
+// exactly, it falls back to the newest semver-compatible version (same major, not older).
+func resolveDistribution(dr libpak.DependencyResolver, version string, compatible bool) (libpak.BuildpackDependency, error) {
+	dep, err := dr.Resolve("gradle", version)
+	if err == nil {
+		return dep, nil
+	} else if !libpak.IsNoValidDependencies(err) {
+		return libpak.BuildpackDependency{}, err
+	}
+
+	if compatible {
+		dep, err = dr.Resolve("gradle", fmt.Sprintf("^%s", version))
+		if err == nil {
+			return dep, nil
+		} else if !libpak.IsNoValidDependencies(err) {
+			return libpak.BuildpackDependency{}, err
+		}
+	}
+
+	return libpak.BuildpackDependency{}, fmt.Errorf("no bundled Gradle distribution satisfies the requested version %s\n%w", version, err)
+}
+
+// taskArguments returns BuildFlags followed by the tasks of BP_GRADLE_TASKS, assemble if not set, and a -x flag for
+// each task of BP_GRADLE_EXCLUDE_TASKS.
+func taskArguments(cr libpak.ConfigurationResolver) ([]string, error) {
+	tasks, err := libbs.ResolveArguments("BP_GRADLE_TASKS", cr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		tasks = []string{"assemble"}
+	}
+
+	excludes, err := libbs.ResolveArguments("BP_GRADLE_EXCLUDE_TASKS", cr)
+	if err != nil {
+		return nil, err
+	}
+
+	args := append(append([]string{}, BuildFlags...), tasks...)
+	for _, task := range excludes {
+		args = append(args, "-x", task)
+	}
+
+	return args, nil
+}
+
+// fileSha256 returns the hex encoded SHA256 of the file at path and false if it does not exist.
+func fileSha256(path string) (string, bool, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, fmt.Errorf("unable to open %s\n%w", path, err)
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", false, fmt.Errorf("unable to read %s\n%w", path, err)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), true, nil
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak/bindings"
+)
+
+// BuildCacheScript configures the HttpBuildCache passed in the BPI_GRADLE_BUILD_CACHE_* environment variables as the
+// remote build cache once the settings have been evaluated, replacing a remote build cache configured by the settings.
+const BuildCacheScript = `// Generated by the Paketo Gradle Buildpack
+import org.gradle.util.GradleVersion
+
+def cacheUrl = System.getenv('BPI_GRADLE_BUILD_CACHE_URL')
+def cachePush = System.getenv('BPI_GRADLE_BUILD_CACHE_PUSH') == 'true'
+def cacheAllowInsecure = System.getenv('BPI_GRADLE_BUILD_CACHE_ALLOW_INSECURE') == 'true'
+def cacheUsername = System.getenv('BPI_GRADLE_BUILD_CACHE_USERNAME')
+def cachePassword = System.getenv('BPI_GRADLE_BUILD_CACHE_PASSWORD')
+
+settingsEvaluated { settings ->
+    settings.buildCache {
+        remote(HttpBuildCache) {
+            enabled = true
+            url = cacheUrl
+            push = cachePush
+            if (GradleVersion.current() >= GradleVersion.version('6.0')) {
+                allowInsecureProtocol = cacheAllowInsecure
+            }
+            if (cacheUsername && cachePassword) {
+                credentials {
+                    username = cacheUsername
+                    password = cachePassword
+                }
+            }
+        }
+    }
+}
+`
+
+// BuildCache is the configuration of the remote build cache init script.
+type BuildCache struct {
+	URL           string
+	Push          bool
+	AllowInsecure bool
+	Username      string
+	Password      string
+}
+
+// NewBuildCache resolves the url, push, allow-insecure and credential secrets of a gradle-build-cache binding. It
+// returns false if there is no such binding.
+func NewBuildCache(platformBindings libcnb.Bindings) (BuildCache, bool, error) {
+	binding, ok, err := bindings.ResolveOne(platformBindings, bindings.OfType("gradle-build-cache"))
+	if err != nil {
+		return BuildCache{}, false, fmt.Errorf("unable to resolve binding\n%w", err)
+	} else if !ok {
+		return BuildCache{}, false, nil
+	}
+
+	var c BuildCache
+
+	c.URL = strings.TrimSpace(binding.Secret["url"])
+	if c.URL == "" {
+		return BuildCache{}, false, fmt.Errorf("binding %s requires a url secret", binding.Name)
+	}
+
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return BuildCache{}, false, fmt.Errorf("invalid url %s in binding %s, must be an absolute HTTP or HTTPS URL", c.URL, binding.Name)
+	}
+
+	for _, f := range []struct {
+		name  string
+		value *bool
+	}{
+		{"push", &c.Push},
+		{"allow-insecure", &c.AllowInsecure},
+	} {
+		s, ok := binding.Secret[f.name]
+		if !ok {
+			continue
+		}
+		if *f.value, err = strconv.ParseBool(strings.TrimSpace(s)); err != nil {
+			return BuildCache{}, false, fmt.Errorf("invalid %s %s in binding %s\n%w", f.name, strings.TrimSpace(s), binding.Name, err)
+		}
+	}
+
+	if u.Scheme == "http" && !c.AllowInsecure {
+		return BuildCache{}, false, fmt.Errorf("binding %s uses the insecure url %s, set its allow-insecure secret to true to allow it", binding.Name, c.URL)
+	}
+
+	if c.Username, c.Password, _, err = BindingCredentials(binding); err != nil {
+		return BuildCache{}, false, err
+	}
+
+	return c, true, nil
+}
+
+// Environment returns the environment variables read by BuildCacheScript.
+func (c BuildCache) Environment() map[string]string {
+	environment := map[string]string{
+		"BPI_GRADLE_BUILD_CACHE_URL":            c.URL,
+		"BPI_GRADLE_BUILD_CACHE_PUSH":           strconv.FormatBool(c.Push),
+		"BPI_GRADLE_BUILD_CACHE_ALLOW_INSECURE": strconv.FormatBool(c.AllowInsecure),
+	}
+
+	if c.Username != "" && c.Password != "" {
+		environment["BPI_GRADLE_BUILD_CACHE_USERNAME"] = c.Username
+		environment["BPI_GRADLE_BUILD_CACHE_PASSWORD"] = c.Password
+	}
+
+	return environment
+}
+
+// RedactedURL returns the URL without a password, for logs and layer metadata.
+func (c BuildCache) RedactedURL() string {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return c.URL
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libbs"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+const (
+	CacheStrategyFingerprint = "fingerprint"
+	CacheStrategyPersistent  = "persistent"
+)
+
+// Cache decorates the libbs.Cache layer over $GRADLE_USER_HOME, recording the Fingerprint of the project's
+// dependencies in the layer metadata. With the fingerprint strategy, the dependency caches of a cache layer with a
+// different fingerprint are removed so that the build starts from a clean dependency cache.
+type Cache struct {
+	Delegate    libbs.Cache
+	Fingerprint string
+	Logger      bard.Logger
+	Strategy    string
+}
+
+func (c Cache) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	previous, _ := layer.Metadata["dependency-fingerprint"].(string)
+
+	caches := filepath.Join(layer.Path, "caches")
+	if ok, err := sherpa.DirExists(caches); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to check for %s\n%w", caches, err)
+	} else if ok && c.Strategy == CacheStrategyFingerprint && previous != c.Fingerprint {
+		if previous == "" {
+			c.Logger.Body("Clearing dependency cache without fingerprint")
+		} else {
+			c.Logger.Bodyf("Clearing dependency cache as the dependency fingerprint changed from %s to %s", previous, c.Fingerprint)
+		}
+		if err := os.RemoveAll(caches); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to remove %s\n%w", caches, err)
+		}
+	}
+
+	c.Delegate.Logger = c.Logger
+	layer, err := c.Delegate.Contribute(layer)
+	if err != nil {
+		return libcnb.Layer{}, err
+	}
+
+	if layer.Metadata == nil {
+		layer.Metadata = map[string]interface{}{}
+	}
+	if c.Fingerprint != "" {
+		layer.Metadata["dependency-fingerprint"] = c.Fingerprint
+	} else {
+		delete(layer.Metadata, "dependency-fingerprint")
+	}
+
+	return layer, nil
+}
+
+func (c Cache) Name() string {
+	return c.Delegate.Name()
+}
+
+// DependencyFingerprint returns the SHA256 of the paths, relative to projectDir, and the contents of the files that
+// pin the project's dependencies: the gradle.lockfile of each project, settings-gradle.lockfile,
+// gradle/libs.versions.toml and gradle/verification-metadata.xml. It is empty if none of them exist.
+func DependencyFingerprint(projectDir string) (string, error) {
+	var files []string
+
+	err := filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", ".gradle", "build", "node_modules":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(projectDir, path)
+		if err != nil {
+			return fmt.Errorf("unable to determine relative path of %s\n%w", path, err)
+		}
+
+		switch {
+		case d.Name() == "gradle.lockfile",
+			d.Name() == "settings-gradle.lockfile",
+			rel == filepath.Join("gradle", "libs.versions.toml"),
+			rel == filepath.Join("gradle", "verification-metadata.xml"):
+			files = append(files, rel)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to find dependency lock files in %s\n%w", projectDir, err)
+	}
+
+	if len(files) == 0 {
+		return "", nil
+	}
+	sort.Strings(files)
+
+	hasher := sha256.New()
+	for _, file := range files {
+		b, err := os.ReadFile(filepath.Join(projectDir, file))
+		if err != nil {
+			return "", fmt.Errorf("unable to read %s\n%w", file, err)
+		}
+		fmt.Fprintf(hasher, "%s\n%d\n", filepath.ToSlash(file), len(b))
+		hasher.Write(b)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
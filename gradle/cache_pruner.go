@@ -0,0 +1,331 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+var (
+	gradleVersionDirectory = regexp.MustCompile(`^\d+\.\d+(\.\d+)?(-.+)?$`)
+	wrapperDistDirectory   = regexp.MustCompile(`^gradle-(.+)-(bin|all)$`)
+	sizePattern            = regexp.MustCompile(`^(\d+)\s*([KMGT]?)I?B?$`)
+)
+
+// CachePruner bounds the size of $GRADLE_USER_HOME, which is persisted in the cache layer across builds, once Gradle
+// has run.
+type CachePruner struct {
+	GradleHome string
+	Logger     bard.Logger
+
+	// MaxSize is the size in bytes $GRADLE_USER_HOME is reduced to by evicting the least recently accessed entries,
+	// unlimited if 0.
+	MaxSize int64
+
+	// Now returns the current time, time.Now if nil.
+	Now func() time.Time
+
+	// Retention is the duration after which entries that have not been accessed are evicted, unlimited if 0.
+	Retention time.Duration
+
+	// Version is the version of Gradle used by the build. Wrapper distributions and version specific caches of
+	// other versions are removed unless it is empty.
+	Version string
+}
+
+// cacheEntry is a unit of the cache that is evicted as a whole, e.g. a version of a module in caches/modules-2.
+type cacheEntry struct {
+	accessed time.Time
+	path     string
+	size     int64
+}
+
+// Prune removes the wrapper distributions and caches of other Gradle versions, then the entries not accessed within
+// Retention and finally the least recently accessed entries until $GRADLE_USER_HOME fits into MaxSize.
+func (c CachePruner) Prune() error {
+	// $GRADLE_USER_HOME is a symlink to the cache layer which filepath.WalkDir does not follow
+	home, err := filepath.EvalSymlinks(c.GradleHome)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to resolve %s\n%w", c.GradleHome, err)
+	}
+	c.GradleHome = home
+
+	now := time.Now
+	if c.Now != nil {
+		now = c.Now
+	}
+
+	if c.Version != "" {
+		if err := c.pruneVersions(); err != nil {
+			return err
+		}
+	}
+
+	if c.Retention <= 0 && c.MaxSize <= 0 {
+		return nil
+	}
+
+	entries, err := c.entries()
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].accessed.Before(entries[j].accessed) })
+
+	if c.Retention > 0 {
+		cutoff := now().Add(-c.Retention)
+
+		var freed int64
+		var count int
+		for len(entries) > 0 && entries[0].accessed.Before(cutoff) {
+			if err := os.RemoveAll(entries[0].path); err != nil {
+				return fmt.Errorf("unable to remove %s\n%w", entries[0].path, err)
+			}
+			freed += entries[0].size
+			count++
+			entries = entries[1:]
+		}
+
+		if count > 0 {
+			c.Logger.Bodyf("Evicted %d cache entries not accessed in %s, freeing %s", count, c.Retention, formatSize(freed))
+		}
+	}
+
+	if c.MaxSize > 0 {
+		size, err := directorySize(c.GradleHome)
+		if err != nil {
+			return err
+		}
+
+		var freed int64
+		var count int
+		for size > c.MaxSize && len(entries) > 0 {
+			if err := os.RemoveAll(entries[0].path); err != nil {
+				return fmt.Errorf("unable to remove %s\n%w", entries[0].path, err)
+			}
+			size -= entries[0].size
+			freed += entries[0].size
+			count++
+			entries = entries[1:]
+		}
+
+		if count > 0 {
+			c.Logger.Bodyf("Evicted %d least recently accessed cache entries to fit into %s, freeing %s", count, formatSize(c.MaxSize), formatSize(freed))
+		}
+		if size > c.MaxSize {
+			c.Logger.Bodyf("WARNING: %s is %s after eviction, exceeding %s", c.GradleHome, formatSize(size), formatSize(c.MaxSize))
+		}
+	}
+
+	return nil
+}
+
+// pruneVersions removes wrapper/dists/gradle-<version>-<type> and the caches/<version>, daemon/<version> and
+// notifications/<version> directories of versions other than Version.
+func (c CachePruner) pruneVersions() error {
+	var stale []string
+
+	dists := filepath.Join(c.GradleHome, "wrapper", "dists")
+	if err := eachDirectory(dists, func(name string) {
+		if m := wrapperDistDirectory.FindStringSubmatch(name); m != nil && !sameVersion(m[1], c.Version) {
+			stale = append(stale, filepath.Join(dists, name))
+		}
+	}); err != nil {
+		return err
+	}
+
+	for _, dir := range []string{"caches", "daemon", "notifications"} {
+		parent := filepath.Join(c.GradleHome, dir)
+		if err := eachDirectory(parent, func(name string) {
+			if gradleVersionDirectory.MatchString(name) && !sameVersion(name, c.Version) {
+				stale = append(stale, filepath.Join(parent, name))
+			}
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range stale {
+		size, err := directorySize(path)
+		if err != nil {
+			return err
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("unable to remove %s\n%w", path, err)
+		}
+
+		rel, _ := filepath.Rel(c.GradleHome, path)
+		c.Logger.Bodyf("Removed %s of an unused Gradle version, freeing %s", rel, formatSize(size))
+	}
+
+	return nil
+}
+
+// entries returns the evictable entries of the caches shared by all Gradle versions: module versions in
+// caches/modules-2/files-2.1, artifact transforms in caches/transforms-*, jars in caches/jars-* and the entries of the
+// local build cache in caches/build-cache-*.
+func (c CachePruner) entries() ([]cacheEntry, error) {
+	caches := filepath.Join(c.GradleHome, "caches")
+
+	patterns := []string{
+		filepath.Join(caches, "modules-2", "files-2.1", "*", "*", "*"),
+		filepath.Join(caches, "transforms-*", "*"),
+		filepath.Join(caches, "jars-*", "*"),
+		filepath.Join(caches, "build-cache-*", "*"),
+	}
+
+	var entries []cacheEntry
+	for _, pattern := range patterns {
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find cache entries %s\n%w", pattern, err)
+		}
+
+		for _, path := range paths {
+			// lock and property files of the caches themselves are not entries
+			if base := filepath.Base(path); strings.HasSuffix(base, ".lock") || base == "gc.properties" {
+				continue
+			}
+
+			entry := cacheEntry{path: path}
+			if err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+				// the times of directories change when entries are created in them, not when they are used
+				if err != nil || d.IsDir() {
+					return err
+				}
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				entry.size += info.Size()
+				if accessed := accessTime(info); accessed.After(entry.accessed) {
+					entry.accessed = accessed
+				}
+				return nil
+			}); err != nil {
+				return nil, fmt.Errorf("unable to read cache entry %s\n%w", path, err)
+			}
+
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// ParseSize parses a size such as 512M, 10G or 10GiB into bytes. Sizes without a unit are bytes.
+func ParseSize(s string) (int64, error) {
+	match := sizePattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(s)))
+	if match == nil {
+		return 0, fmt.Errorf("invalid size %s, expected a number of bytes optionally followed by K, M, G or T", s)
+	}
+
+	size, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse size %s\n%w", s, err)
+	}
+
+	switch match[2] {
+	case "K":
+		size *= KiB
+	case "M":
+		size *= MiB
+	case "G":
+		size *= GiB
+	case "T":
+		size *= 1024 * GiB
+	}
+
+	return size, nil
+}
+
+func formatSize(size int64) string {
+	switch {
+	case size >= GiB:
+		return fmt.Sprintf("%.1fG", float64(size)/float64(GiB))
+	case size >= MiB:
+		return fmt.Sprintf("%.1fM", float64(size)/float64(MiB))
+	case size >= KiB:
+		return fmt.Sprintf("%.1fK", float64(size)/float64(KiB))
+	default:
+		return fmt.Sprintf("%dB", size)
+	}
+}
+
+func directorySize(path string) (int64, error) {
+	var size int64
+
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if os.IsNotExist(err) && p == path {
+			return filepath.SkipDir
+		} else if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to determine size of %s\n%w", path, err)
+	}
+
+	return size, nil
+}
+
+// sameVersion returns whether two Gradle versions are equal, e.g. 8.5 and 8.5.0.
+func sameVersion(a string, b string) bool {
+	va, errA := semver.NewVersion(a)
+	vb, errB := semver.NewVersion(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return va.Equal(vb)
+}
+
+func eachDirectory(parent string, f func(name string)) error {
+	entries, err := os.ReadDir(parent)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to read %s\n%w", parent, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			f(entry.Name())
+		}
+	}
+
+	return nil
+}
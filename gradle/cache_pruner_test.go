@@ -0,0 +1,163 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/gradle/v7/gradle"
+)
+
+func testCachePruner(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		now    time.Time
+		output *bytes.Buffer
+		pruner gradle.CachePruner
+	)
+
+	write := func(path string, size int, accessed time.Time) {
+		file := filepath.Join(pruner.GradleHome, path)
+		Expect(os.MkdirAll(filepath.Dir(file), 0755)).To(Succeed())
+		Expect(os.WriteFile(file, make([]byte, size), 0644)).To(Succeed())
+		Expect(os.Chtimes(file, accessed, accessed)).To(Succeed())
+	}
+
+	it.Before(func() {
+		now = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		output = &bytes.Buffer{}
+		pruner = gradle.CachePruner{
+			GradleHome: t.TempDir(),
+			Logger:     bard.NewLogger(output),
+			Now:        func() time.Time { return now },
+		}
+	})
+
+	context("Version is set", func() {
+		it.Before(func() {
+			pruner.Version = "8.5.0"
+
+			write("wrapper/dists/gradle-8.5-bin/hash/gradle-8.5/bin/gradle", 10, now)
+			write("wrapper/dists/gradle-8.4-bin/hash/gradle-8.4/bin/gradle", 10, now)
+			write("wrapper/dists/gradle-7.6-all/hash/gradle-7.6/bin/gradle", 10, now)
+			write("caches/8.5/generated-gradle-jars/gradle-api-8.5.jar", 10, now)
+			write("caches/8.4/generated-gradle-jars/gradle-api-8.4.jar", 10, now)
+			write("caches/modules-2/modules-2.lock", 10, now)
+			write("daemon/8.4/registry.bin", 10, now)
+			write("notifications/8.4/release-features.rendered", 10, now)
+		})
+
+		it("removes wrapper distributions and caches of other versions", func() {
+			Expect(pruner.Prune()).To(Succeed())
+
+			Expect(filepath.Join(pruner.GradleHome, "wrapper", "dists", "gradle-8.5-bin")).To(BeADirectory())
+			Expect(filepath.Join(pruner.GradleHome, "wrapper", "dists", "gradle-8.4-bin")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(pruner.GradleHome, "wrapper", "dists", "gradle-7.6-all")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(pruner.GradleHome, "caches", "8.5")).To(BeADirectory())
+			Expect(filepath.Join(pruner.GradleHome, "caches", "8.4")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(pruner.GradleHome, "caches", "modules-2")).To(BeADirectory())
+			Expect(filepath.Join(pruner.GradleHome, "daemon", "8.4")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(pruner.GradleHome, "notifications", "8.4")).NotTo(BeAnExistingFile())
+
+			Expect(output.String()).To(ContainSubstring("Removed wrapper/dists/gradle-8.4-bin of an unused Gradle version, freeing 10B"))
+		})
+	})
+
+	context("cache entries exist", func() {
+		var module, transform, buildCache string
+
+		it.Before(func() {
+			module = filepath.Join("caches", "modules-2", "files-2.1", "com.example", "lib", "1.0")
+			transform = filepath.Join("caches", "transforms-4", "0123abcd")
+			buildCache = filepath.Join("caches", "build-cache-1", "4567efgh")
+
+			write(filepath.Join(module, "hash", "lib-1.0.jar"), 2048, now.Add(-10*24*time.Hour))
+			write(filepath.Join(transform, "transformed", "lib.jar"), 1024, now.Add(-2*24*time.Hour))
+			write(buildCache, 1024, now.Add(-1*time.Hour))
+			write(filepath.Join("caches", "build-cache-1", "gc.properties"), 0, now.Add(-30*24*time.Hour))
+		})
+
+		it("evicts entries not accessed within the retention", func() {
+			pruner.Retention = 7 * 24 * time.Hour
+
+			Expect(pruner.Prune()).To(Succeed())
+
+			Expect(filepath.Join(pruner.GradleHome, module)).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(pruner.GradleHome, transform)).To(BeADirectory())
+			Expect(filepath.Join(pruner.GradleHome, buildCache)).To(BeARegularFile())
+			Expect(filepath.Join(pruner.GradleHome, "caches", "build-cache-1", "gc.properties")).To(BeARegularFile())
+			Expect(output.String()).To(ContainSubstring("Evicted 1 cache entries not accessed in 168h0m0s, freeing 2.0K"))
+		})
+
+		it("evicts the least recently accessed entries to fit into the maximum size", func() {
+			pruner.MaxSize = 1536
+
+			Expect(pruner.Prune()).To(Succeed())
+
+			Expect(filepath.Join(pruner.GradleHome, module)).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(pruner.GradleHome, transform)).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(pruner.GradleHome, buildCache)).To(BeARegularFile())
+			Expect(output.String()).To(ContainSubstring("Evicted 2 least recently accessed cache entries to fit into 1.5K, freeing 3.0K"))
+		})
+
+		it("evicts entries of a $GRADLE_USER_HOME linked to the cache layer", func() {
+			home := filepath.Join(t.TempDir(), ".gradle")
+			Expect(os.Symlink(pruner.GradleHome, home)).To(Succeed())
+			layer := pruner.GradleHome
+			pruner.GradleHome = home
+			pruner.MaxSize = 1536
+
+			Expect(pruner.Prune()).To(Succeed())
+
+			Expect(filepath.Join(layer, module)).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(layer, transform)).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(layer, buildCache)).To(BeARegularFile())
+			Expect(home).To(BeADirectory())
+			Expect(output.String()).To(ContainSubstring("Evicted 2 least recently accessed cache entries to fit into 1.5K, freeing 3.0K"))
+		})
+
+		it("does nothing without limits", func() {
+			Expect(pruner.Prune()).To(Succeed())
+
+			Expect(filepath.Join(pruner.GradleHome, module)).To(BeADirectory())
+			Expect(output.String()).To(BeEmpty())
+		})
+	})
+
+	context("ParseSize", func() {
+		it("parses sizes", func() {
+			Expect(gradle.ParseSize("1024")).To(Equal(int64(1024)))
+			Expect(gradle.ParseSize("512M")).To(Equal(512 * gradle.MiB))
+			Expect(gradle.ParseSize("10g")).To(Equal(10 * gradle.GiB))
+			Expect(gradle.ParseSize("2GiB")).To(Equal(2 * gradle.GiB))
+		})
+
+		it("fails on invalid sizes", func() {
+			_, err := gradle.ParseSize("ten gigabytes")
+			Expect(err).To(MatchError("invalid size ten gigabytes, expected a number of bytes optionally followed by K, M, G or T"))
+		})
+	})
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libbs"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/gradle/v7/gradle"
+)
+
+func testCache(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cache      gradle.Cache
+		ctx        libcnb.BuildContext
+		layer      libcnb.Layer
+		projectDir string
+	)
+
+	it.Before(func() {
+		var err error
+
+		ctx.Layers.Path = t.TempDir()
+		projectDir = t.TempDir()
+
+		cache = gradle.Cache{
+			Delegate:    libbs.Cache{Path: filepath.Join(t.TempDir(), ".gradle")},
+			Fingerprint: "new-fingerprint",
+			Strategy:    gradle.CacheStrategyFingerprint,
+		}
+
+		layer, err = ctx.Layers.Layer(cache.Name())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(layer.Path, "caches", "modules-2"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(layer.Path, "wrapper", "dists"), 0755)).To(Succeed())
+	})
+
+	it("records the fingerprint in the layer metadata", func() {
+		layer, err := cache.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(cache.Name()).To(Equal("cache"))
+		Expect(layer.Cache).To(BeTrue())
+		Expect(layer.Metadata).To(HaveKeyWithValue("dependency-fingerprint", "new-fingerprint"))
+	})
+
+	it("clears the dependency caches if the fingerprint changed", func() {
+		layer.Metadata = map[string]interface{}{"dependency-fingerprint": "old-fingerprint"}
+
+		_, err := cache.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filepath.Join(layer.Path, "caches")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(layer.Path, "wrapper", "dists")).To(BeADirectory())
+	})
+
+	it("keeps the dependency caches if the fingerprint is unchanged", func() {
+		layer.Metadata = map[string]interface{}{"dependency-fingerprint": "new-fingerprint"}
+
+		_, err := cache.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filepath.Join(layer.Path, "caches", "modules-2")).To(BeADirectory())
+	})
+
+	it("keeps the dependency caches with the persistent strategy", func() {
+		cache.Strategy = gradle.CacheStrategyPersistent
+		layer.Metadata = map[string]interface{}{"dependency-fingerprint": "old-fingerprint"}
+
+		layer, err := cache.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filepath.Join(layer.Path, "caches", "modules-2")).To(BeADirectory())
+		Expect(layer.Metadata).To(HaveKeyWithValue("dependency-fingerprint", "new-fingerprint"))
+	})
+
+	context("DependencyFingerprint", func() {
+
+		it("is empty without lock files", func() {
+			writeFile(t, projectDir, "build.gradle", "")
+
+			Expect(gradle.DependencyFingerprint(projectDir)).To(BeEmpty())
+		})
+
+		it("changes with lock files, version catalogs and verification metadata", func() {
+			writeFile(t, projectDir, "gradle.lockfile", "com.example:lib:1.0=compileClasspath\n")
+			writeFile(t, projectDir, "lib/gradle.lockfile", "com.example:other:1.0=compileClasspath\n")
+			writeFile(t, projectDir, "settings-gradle.lockfile", "empty=incomingCatalogForLibs0\n")
+			writeFile(t, projectDir, "gradle/libs.versions.toml", "[versions]\n")
+			writeFile(t, projectDir, "gradle/verification-metadata.xml", "<verification-metadata/>")
+			writeFile(t, projectDir, "build/gradle.lockfile", "ignored")
+
+			fingerprint, err := gradle.DependencyFingerprint(projectDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fingerprint).To(HaveLen(64))
+
+			writeFile(t, projectDir, "build/gradle.lockfile", "still ignored")
+			Expect(gradle.DependencyFingerprint(projectDir)).To(Equal(fingerprint))
+
+			writeFile(t, projectDir, "lib/gradle.lockfile", "com.example:other:1.1=compileClasspath\n")
+			Expect(gradle.DependencyFingerprint(projectDir)).NotTo(Equal(fingerprint))
+		})
+	})
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	KiB = int64(1024)
+	MiB = 1024 * KiB
+	GiB = 1024 * MiB
+)
+
+// ContainerResources reads the memory and CPU limits of the container from the cgroup v2 or v1 file system below Root,
+// falling back to the resources of the host.
+type ContainerResources struct {
+	Root string
+}
+
+// Memory returns the memory available to the container in bytes, or 0 if it cannot be determined.
+func (c ContainerResources) Memory() (int64, error) {
+	host, err := c.hostMemory()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, file := range []string{
+		filepath.Join(c.Root, "sys", "fs", "cgroup", "memory.max"),
+		filepath.Join(c.Root, "sys", "fs", "cgroup", "memory", "memory.limit_in_bytes"),
+	} {
+		s, ok, err := c.read(file)
+		if err != nil {
+			return 0, err
+		} else if !ok || s == "max" {
+			continue
+		}
+
+		limit, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unable to parse %s\n%w", file, err)
+		}
+
+		// cgroup v1 reports no limit as a value close to the maximum int64
+		if limit <= 0 || limit >= math.MaxInt64/2 {
+			continue
+		}
+
+		if host > 0 && host < limit {
+			return host, nil
+		}
+		return limit, nil
+	}
+
+	return host, nil
+}
+
+// CPUs returns the number of CPUs available to the container, rounded up.
+func (c ContainerResources) CPUs() (int, error) {
+	var quota, period string
+
+	if s, ok, err := c.read(filepath.Join(c.Root, "sys", "fs", "cgroup", "cpu.max")); err != nil {
+		return 0, err
+	} else if ok {
+		fields := strings.Fields(s)
+		if len(fields) == 2 && fields[0] != "max" {
+			quota, period = fields[0], fields[1]
+		}
+	} else if s, ok, err := c.read(filepath.Join(c.Root, "sys", "fs", "cgroup", "cpu", "cpu.cfs_quota_us")); err != nil {
+		return 0, err
+	} else if ok && s != "-1" {
+		quota = s
+		if period, _, err = c.read(filepath.Join(c.Root, "sys", "fs", "cgroup", "cpu", "cpu.cfs_period_us")); err != nil {
+			return 0, err
+		}
+	}
+
+	if quota != "" && period != "" {
+		q, err := strconv.ParseFloat(quota, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unable to parse CPU quota %s\n%w", quota, err)
+		}
+		p, err := strconv.ParseFloat(period, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unable to parse CPU period %s\n%w", period, err)
+		}
+
+		if q > 0 && p > 0 {
+			cpus := int(math.Ceil(q / p))
+			if cpus < runtime.NumCPU() {
+				return cpus, nil
+			}
+		}
+	}
+
+	return runtime.NumCPU(), nil
+}
+
+// hostMemory returns MemTotal of /proc/meminfo in bytes, or 0 if it does not exist.
+func (c ContainerResources) hostMemory() (int64, error) {
+	file := filepath.Join(c.Root, "proc", "meminfo")
+	in, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("unable to open %s\n%w", file, err)
+	}
+	defer in.Close()
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemTotal:" {
+			continue
+		}
+
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unable to parse MemTotal of %s\n%w", file, err)
+		}
+		return kb * KiB, nil
+	}
+
+	return 0, scanner.Err()
+}
+
+func (ContainerResources) read(file string) (string, bool, error) {
+	b, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	return strings.TrimSpace(string(b)), true, nil
+}
+
+// DaemonJVMArgs returns the heap and metaspace sizes of the JVM running the build for the memory of the container: half
+// of it for the heap, between 256M and 8G, and an eighth for metaspace, between 128M and 512M.
+func DaemonJVMArgs(memory int64) []string {
+	heap := clamp(memory/2, 256*MiB, 8*GiB)
+	metaspace := clamp(memory/8, 128*MiB, 512*MiB)
+
+	return []string{
+		fmt.Sprintf("-Xmx%dm", heap/MiB),
+		fmt.Sprintf("-XX:MaxMetaspaceSize=%dm", metaspace/MiB),
+	}
+}
+
+// Workers returns the number of workers for the CPUs of the container, reduced so that each worker, e.g. a forked
+// test JVM, has about 512M of the memory not used by the heap of the build JVM.
+func Workers(memory int64, cpus int) int {
+	workers := int64(cpus)
+
+	if memory > 0 {
+		if available := (memory - clamp(memory/2, 256*MiB, 8*GiB)) / (512 * MiB); available < workers {
+			workers = available
+		}
+	}
+
+	if workers < 1 {
+		return 1
+	}
+	return int(workers)
+}
+
+func clamp(value int64, min int64, max int64) int64 {
+	if value < min {
+		return min
+	} else if value > max {
+		return max
+	}
+	return value
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/gradle/v7/gradle"
+)
+
+func testContainer(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		resources gradle.ContainerResources
+	)
+
+	it.Before(func() {
+		var err error
+		resources.Root, err = os.MkdirTemp("", "container")
+		Expect(err).NotTo(HaveOccurred())
+
+		writeFile(t, resources.Root, "proc/meminfo", "MemTotal:       16777216 kB\nMemFree:         1024 kB\n")
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(resources.Root)).To(Succeed())
+	})
+
+	context("Memory", func() {
+		it("reads the cgroup v2 limit", func() {
+			writeFile(t, resources.Root, "sys/fs/cgroup/memory.max", "2147483648\n")
+
+			Expect(resources.Memory()).To(Equal(2 * gradle.GiB))
+		})
+
+		it("reads the cgroup v1 limit", func() {
+			writeFile(t, resources.Root, "sys/fs/cgroup/memory/memory.limit_in_bytes", "1073741824\n")
+
+			Expect(resources.Memory()).To(Equal(gradle.GiB))
+		})
+
+		it("falls back to the host memory without a limit", func() {
+			writeFile(t, resources.Root, "sys/fs/cgroup/memory.max", "max\n")
+			writeFile(t, resources.Root, "sys/fs/cgroup/memory/memory.limit_in_bytes", "9223372036854771712\n")
+
+			Expect(resources.Memory()).To(Equal(16 * gradle.GiB))
+		})
+
+		it("caps the limit at the host memory", func() {
+			writeFile(t, resources.Root, "sys/fs/cgroup/memory.max", "68719476736\n")
+
+			Expect(resources.Memory()).To(Equal(16 * gradle.GiB))
+		})
+
+		it("returns 0 if nothing is known", func() {
+			Expect(os.Remove(filepath.Join(resources.Root, "proc", "meminfo"))).To(Succeed())
+
+			Expect(resources.Memory()).To(BeZero())
+		})
+	})
+
+	context("CPUs", func() {
+		it("reads the cgroup v2 quota", func() {
+			writeFile(t, resources.Root, "sys/fs/cgroup/cpu.max", "50000 100000\n")
+
+			Expect(resources.CPUs()).To(Equal(1))
+		})
+
+		it("reads the cgroup v1 quota", func() {
+			writeFile(t, resources.Root, "sys/fs/cgroup/cpu/cpu.cfs_quota_us", "100000\n")
+			writeFile(t, resources.Root, "sys/fs/cgroup/cpu/cpu.cfs_period_us", "100000\n")
+
+			Expect(resources.CPUs()).To(Equal(1))
+		})
+
+		it("falls back to the host CPUs without a quota", func() {
+			writeFile(t, resources.Root, "sys/fs/cgroup/cpu.max", "max 100000\n")
+
+			Expect(resources.CPUs()).To(Equal(runtime.NumCPU()))
+		})
+	})
+
+	context("DaemonJVMArgs", func() {
+		it("sizes heap and metaspace", func() {
+			Expect(gradle.DaemonJVMArgs(4 * gradle.GiB)).To(Equal([]string{"-Xmx2048m", "-XX:MaxMetaspaceSize=512m"}))
+			Expect(gradle.DaemonJVMArgs(512 * gradle.MiB)).To(Equal([]string{"-Xmx256m", "-XX:MaxMetaspaceSize=128m"}))
+			Expect(gradle.DaemonJVMArgs(64 * gradle.GiB)).To(Equal([]string{"-Xmx8192m", "-XX:MaxMetaspaceSize=512m"}))
+		})
+	})
+
+	context("Workers", func() {
+		it("limits workers by CPUs and memory", func() {
+			Expect(gradle.Workers(4*gradle.GiB, 8)).To(Equal(4))
+			Expect(gradle.Workers(64*gradle.GiB, 8)).To(Equal(8))
+			Expect(gradle.Workers(512*gradle.MiB, 8)).To(Equal(1))
+			Expect(gradle.Workers(0, 2)).To(Equal(2))
+		})
+	})
+}
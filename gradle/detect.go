@@ -23,6 +23,7 @@ import (
 	"github.com/paketo-buildpacks/libpak/bard"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -46,11 +47,17 @@ func (Detect) Detect(context libcnb.DetectContext) (libcnb.DetectResult, error)
 		return libcnb.DetectResult{}, err
 	}
 
+	projectPath, err := ProjectPath(cr)
+	if err != nil {
+		return libcnb.DetectResult{}, err
+	}
+	projectDir := filepath.Join(context.Application.Path, projectPath)
+
 	buildFile, _ := cr.Resolve("BP_GRADLE_BUILD_FILE")
 
 	if buildFile != "" {
 
-		file := filepath.Join(context.Application.Path, buildFile)
+		file := filepath.Join(projectDir, buildFile)
 		_, err = os.Stat(file)
 		if os.IsNotExist(err) {
 			l.Logger.Infof("SKIPPED: BP_GRADLE_BUILD_FILE was specified but %s could not be found", file)
@@ -64,14 +71,14 @@ func (Detect) Detect(context libcnb.DetectContext) (libcnb.DetectResult, error)
 	var files []string
 	if buildFile != "" {
 		files = []string{
-			filepath.Join(context.Application.Path, buildFile),
+			filepath.Join(projectDir, buildFile),
 		}
 	} else {
 		files = []string{
-			filepath.Join(context.Application.Path, "build.gradle"),
-			filepath.Join(context.Application.Path, "build.gradle.kts"),
-			filepath.Join(context.Application.Path, "settings.gradle"),
-			filepath.Join(context.Application.Path, "settings.gradle.kts"),
+			filepath.Join(projectDir, "build.gradle"),
+			filepath.Join(projectDir, "build.gradle.kts"),
+			filepath.Join(projectDir, "settings.gradle"),
+			filepath.Join(projectDir, "settings.gradle.kts"),
 		}
 	}
 	if err := findFile(files, func(file string) bool {
@@ -98,6 +105,19 @@ func (Detect) Detect(context libcnb.DetectContext) (libcnb.DetectResult, error)
 
 	// Gradle's detection has passed
 	if len(result.Plans) > 0 {
+		if _, ok := cr.Resolve("BP_JVM_VERSION"); !ok {
+			if version, ok := ResolveJDKVersion(projectDir, l); ok {
+				for i, require := range result.Plans[0].Requires {
+					if require.Name == PlanEntryJDK {
+						result.Plans[0].Requires[i].Metadata = map[string]interface{}{
+							"version":        strconv.Itoa(version.Version),
+							"version-source": version.Source,
+						}
+					}
+				}
+			}
+		}
+
 		if cr.ResolveBool("BP_JAVA_INSTALL_NODE") {
 			var fileFound bool
 			files := []string{filepath.Join(context.Application.Path, "yarn.lock"), filepath.Join(context.Application.Path, "package.json")}
@@ -103,6 +103,72 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 		}))
 	})
 
+	context("the build declares a Java version", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "build.gradle"),
+				[]byte("java { toolchain { languageVersion = JavaLanguageVersion.of(21) } }"), 0644)).To(Succeed())
+		})
+
+		it("requires the JDK version", func() {
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Plans[0].Requires).To(ContainElement(libcnb.BuildPlanRequire{
+				Name:     "jdk",
+				Metadata: map[string]interface{}{"version": "21", "version-source": "build.gradle"},
+			}))
+		})
+
+		it("does not require a version if BP_JVM_VERSION is set", func() {
+			t.Setenv("BP_JVM_VERSION", "17")
+
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Plans[0].Requires).To(ContainElement(libcnb.BuildPlanRequire{Name: "jdk"}))
+		})
+	})
+
+	it("passes if the version catalog cannot be parsed", func() {
+		Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "build.gradle"), []byte{}, 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "gradle"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "gradle", "libs.versions.toml"), []byte("[versions\n"), 0644)).To(Succeed())
+
+		result, err := detect.Detect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(result.Pass).To(BeTrue())
+		Expect(result.Plans[0].Requires).To(ContainElement(libcnb.BuildPlanRequire{Name: "jdk"}))
+	})
+
+	context("BP_GRADLE_PROJECT_PATH is set", func() {
+		it.Before(func() {
+			t.Setenv("BP_GRADLE_PROJECT_PATH", "services/api")
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "services", "api"), 0755)).To(Succeed())
+		})
+
+		it("passes with build.gradle in the project", func() {
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "services", "api", "build.gradle"), []byte{}, 0644)).To(Succeed())
+
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Pass).To(BeTrue())
+		})
+
+		it("fails with build.gradle only in the application root", func() {
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "build.gradle"), []byte{}, 0644)).To(Succeed())
+
+			Expect(detect.Detect(ctx)).To(Equal(libcnb.DetectResult{}))
+		})
+
+		it("fails outside the application", func() {
+			t.Setenv("BP_GRADLE_PROJECT_PATH", "../other")
+
+			_, err := detect.Detect(ctx)
+			Expect(err).To(MatchError("invalid BP_GRADLE_PROJECT_PATH ../other, must be a directory relative to the application"))
+		})
+	})
+
 	it("passes with build.gradle.kts", func() {
 		Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "build.gradle.kts"), []byte{}, 0644))
 
@@ -31,10 +31,14 @@ type Distribution struct {
 	Logger           bard.Logger
 }
 
-func NewDistribution(dependency libpak.BuildpackDependency, cache libpak.DependencyCache) (Distribution, libcnb.BOMEntry) {
+func NewDistribution(dependency libpak.BuildpackDependency, version string, cache libpak.DependencyCache) (Distribution, libcnb.BOMEntry) {
 	contributor, entry := libpak.NewDependencyLayer(dependency, cache, libcnb.LayerTypes{
 		Cache: true,
 	})
+	contributor.ExpectedMetadata = map[string]interface{}{
+		"dependency":        dependency,
+		"requested-version": version,
+	}
 	return Distribution{LayerContributor: contributor}, entry
 }
 
@@ -54,7 +54,7 @@ func testDistribution(t *testing.T, context spec.G, it spec.S) {
 		}
 		dc := libpak.DependencyCache{CachePath: "testdata"}
 
-		d, _ := gradle.NewDistribution(dep, dc)
+		d, _ := gradle.NewDistribution(dep, "", dc)
 		layer, err := ctx.Layers.Layer("test-layer")
 		Expect(err).NotTo(HaveOccurred())
 
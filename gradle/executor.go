@@ -0,0 +1,140 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+// Executor decorates the effect.Executor that runs Gradle with build-only configuration that must neither be recorded
+// in the application layer metadata nor persisted in the cache layer, such as credentials.
+type Executor struct {
+	Delegate    effect.Executor
+	Environment map[string]string
+
+	// Dir replaces the working directory of the execution, e.g. with the directory of BP_GRADLE_PROJECT_PATH, if set.
+	Dir string
+
+	// Files maps target paths, typically in $GRADLE_USER_HOME, to source files that are linked to them while Gradle
+	// runs. Missing sources are ignored.
+	Files map[string]string
+
+	// After are called once Gradle has run, whether or not it succeeded, e.g. to export test reports.
+	After []func() error
+}
+
+func (e Executor) Execute(execution effect.Execution) (err error) {
+	if e.Dir != "" {
+		execution.Dir = e.Dir
+	}
+
+	if len(execution.Env) == 0 {
+		execution.Env = os.Environ()
+	}
+
+	for _, name := range sortedKeys(e.Environment) {
+		execution.Env = append(execution.Env, fmt.Sprintf("%s=%s", name, e.Environment[name]))
+	}
+
+	var restores []func() error
+	defer func() {
+		for i := len(restores) - 1; i >= 0; i-- {
+			if rErr := restores[i](); rErr != nil && err == nil {
+				err = rErr
+			}
+		}
+	}()
+
+	for _, target := range sortedKeys(e.Files) {
+		restore, err := link(e.Files[target], target)
+		if err != nil {
+			return err
+		}
+		restores = append(restores, restore)
+	}
+
+	err = e.Delegate.Execute(execution)
+
+	for _, after := range e.After {
+		if aErr := after(); aErr != nil && err == nil {
+			err = aErr
+		}
+	}
+
+	return err
+}
+
+// link symlinks target to source, moving a file already present at target aside, and returns a function that removes
+// the link and restores the original file.
+func link(source string, target string) (func() error, error) {
+	if ok, err := sherpa.Exists(source); err != nil {
+		return nil, fmt.Errorf("unable to check for %s\n%w", source, err)
+	} else if !ok {
+		return func() error { return nil }, nil
+	}
+
+	backup := ""
+	if fi, err := os.Lstat(target); err == nil {
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if err := os.Remove(target); err != nil {
+				return nil, fmt.Errorf("unable to remove symlink %s\n%w", target, err)
+			}
+		} else {
+			backup = fmt.Sprintf("%s.orig", target)
+			if err := os.Rename(target, backup); err != nil {
+				return nil, fmt.Errorf("unable to move %s to %s\n%w", target, backup, err)
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("unable to stat %s\n%w", target, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return nil, fmt.Errorf("unable to create directory %s\n%w", filepath.Dir(target), err)
+	}
+
+	if err := os.Symlink(source, target); err != nil {
+		return nil, fmt.Errorf("unable to link %s to %s\n%w", target, source, err)
+	}
+
+	return func() error {
+		if err := os.Remove(target); err != nil {
+			return fmt.Errorf("unable to remove %s\n%w", target, err)
+		}
+		if backup != "" {
+			if err := os.Rename(backup, target); err != nil {
+				return fmt.Errorf("unable to restore %s\n%w", target, err)
+			}
+		}
+		return nil
+	}, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
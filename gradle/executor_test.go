@@ -0,0 +1,193 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/gradle/v7/gradle"
+)
+
+func testExecutor(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		delegate *FakeExecutor
+		executor gradle.Executor
+	)
+
+	it.Before(func() {
+		delegate = &FakeExecutor{}
+		executor = gradle.Executor{
+			Delegate:    delegate,
+			Environment: map[string]string{"JAVA_OPTS": "-Dgradle.wrapperUser=user"},
+		}
+	})
+
+	it("adds the environment to the execution", func() {
+		Expect(executor.Execute(effect.Execution{Command: "gradlew", Env: []string{"A=B"}})).To(Succeed())
+
+		Expect(delegate.Executions).To(HaveLen(1))
+		Expect(delegate.Executions[0].Command).To(Equal("gradlew"))
+		Expect(delegate.Executions[0].Env).To(Equal([]string{"A=B", "JAVA_OPTS=-Dgradle.wrapperUser=user"}))
+	})
+
+	it("replaces the working directory", func() {
+		executor.Dir = "/workspace/services/api"
+
+		Expect(executor.Execute(effect.Execution{Command: "gradlew", Dir: "/workspace"})).To(Succeed())
+
+		Expect(delegate.Executions[0].Dir).To(Equal("/workspace/services/api"))
+	})
+
+	it("starts from the process environment", func() {
+		t.Setenv("TEST_EXECUTOR", "test-value")
+
+		Expect(executor.Execute(effect.Execution{Command: "gradlew"})).To(Succeed())
+
+		Expect(delegate.Executions[0].Env).To(ContainElements("TEST_EXECUTOR=test-value", "JAVA_OPTS=-Dgradle.wrapperUser=user"))
+	})
+
+	context("after", func() {
+		var calls int
+
+		it.Before(func() {
+			calls = 0
+			executor.After = []func() error{func() error {
+				calls++
+				return nil
+			}}
+		})
+
+		it("calls after functions once executed", func() {
+			Expect(executor.Execute(effect.Execution{Command: "gradlew"})).To(Succeed())
+
+			Expect(calls).To(Equal(1))
+		})
+
+		it("calls after functions when execution fails and returns the execution error", func() {
+			delegate.Err = fmt.Errorf("test-error")
+			executor.After = append(executor.After, func() error { return fmt.Errorf("after-error") })
+
+			Expect(executor.Execute(effect.Execution{Command: "gradlew"})).To(MatchError("test-error"))
+
+			Expect(calls).To(Equal(1))
+		})
+
+		it("returns errors of after functions", func() {
+			executor.After = append(executor.After, func() error { return fmt.Errorf("after-error") })
+
+			Expect(executor.Execute(effect.Execution{Command: "gradlew"})).To(MatchError("after-error"))
+		})
+	})
+
+	context("files", func() {
+		var (
+			gradleHome string
+			source     string
+			target     string
+		)
+
+		it.Before(func() {
+			gradleHome = t.TempDir()
+			target = filepath.Join(gradleHome, "gradle.properties")
+
+			source = filepath.Join(t.TempDir(), "gradle.properties")
+			Expect(os.WriteFile(source, []byte("secret=bound-secret"), 0644)).To(Succeed())
+
+			executor.Files = map[string]string{target: source}
+		})
+
+		it("links files only while executing", func() {
+			delegate.OnExecute = func() error {
+				data, err := os.ReadFile(target)
+				if err != nil {
+					return err
+				}
+				if string(data) != "secret=bound-secret" {
+					return fmt.Errorf("unexpected content %q", data)
+				}
+				return nil
+			}
+
+			Expect(executor.Execute(effect.Execution{Command: "gradlew"})).To(Succeed())
+
+			Expect(filepath.Walk(gradleHome, func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				Expect(string(data)).NotTo(ContainSubstring("bound-secret"), path)
+				return nil
+			})).To(Succeed())
+			Expect(target).NotTo(BeAnExistingFile())
+		})
+
+		it("removes links when execution fails", func() {
+			delegate.Err = fmt.Errorf("test-error")
+
+			Expect(executor.Execute(effect.Execution{Command: "gradlew"})).To(MatchError("test-error"))
+
+			_, err := os.Lstat(target)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		it("restores an existing file", func() {
+			Expect(os.WriteFile(target, []byte("original"), 0644)).To(Succeed())
+
+			Expect(executor.Execute(effect.Execution{Command: "gradlew"})).To(Succeed())
+
+			Expect(os.ReadFile(target)).To(Equal([]byte("original")))
+			Expect(target + ".orig").NotTo(BeAnExistingFile())
+		})
+
+		it("ignores missing sources", func() {
+			Expect(os.Remove(source)).To(Succeed())
+
+			Expect(executor.Execute(effect.Execution{Command: "gradlew"})).To(Succeed())
+
+			Expect(delegate.Executions).To(HaveLen(1))
+			Expect(target).NotTo(BeAnExistingFile())
+		})
+	})
+}
+
+type FakeExecutor struct {
+	Executions []effect.Execution
+	Err        error
+	OnExecute  func() error
+}
+
+func (f *FakeExecutor) Execute(execution effect.Execution) error {
+	f.Executions = append(f.Executions, execution)
+	if f.OnExecute != nil {
+		if err := f.OnExecute(); err != nil {
+			return err
+		}
+	}
+	return f.Err
+}
@@ -4,54 +4,75 @@ import (
 	"fmt"
 	"github.com/magiconair/properties"
 	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sherpa"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/buildpacks/libcnb"
 )
 
+// PropertiesFile contributes a properties file from bindings, later bindings taking precedence over earlier ones. For
+// gradle.properties the file is the merge of, in increasing order of precedence, Defaults, the file already in
+// GradlePropertiesHome, the bindings and Overrides. As Gradle prefers $GRADLE_USER_HOME/gradle.properties over
+// ProjectGradleProperties, Defaults the project sets are skipped and org.gradle.jvmargs defaults to the project's.
+// JVMArgOverrides then replace the arguments of org.gradle.jvmargs they set and JVMArgs are appended unless it already
+// sets them.
 type PropertiesFile struct {
-	Binding                  libcnb.Binding
+	Bindings                 libcnb.Bindings
+	Defaults                 map[string]string
 	GradlePropertiesHome     string
 	GradlePropertiesFileName string
 	GradlePropertiesName     string
+	JVMArgOverrides          []string
+	JVMArgs                  []string
 	Logger                   bard.Logger
+	Overrides                map[string]string
+	ProjectGradleProperties  string
 }
 
 func (p PropertiesFile) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
-	path, ok := p.Binding.SecretFilePath(p.GradlePropertiesFileName)
-	if !ok {
-		return libcnb.Layer{}, nil
+	if p.GradlePropertiesName == "gradle-properties" {
+		return p.contributeGradleProperties(layer)
 	}
 
-	originalPropertiesFilePath := filepath.Join(p.GradlePropertiesHome, p.GradlePropertiesFileName)
-	if p.GradlePropertiesName == "gradle-properties" {
-		p.Logger.Debug("symlinking gradle-properties bound file")
-		gradlePropertiesPath := originalPropertiesFilePath
-		if err := os.Symlink(path, gradlePropertiesPath); os.IsExist(err) {
-			err = os.Remove(gradlePropertiesPath)
-			if err != nil {
-				return libcnb.Layer{}, fmt.Errorf("unable to remove old symlink for %s\n%w", p.GradlePropertiesFileName, err)
-			}
+	var bound []*properties.Properties
+	for _, binding := range p.Bindings {
+		path, ok := binding.SecretFilePath(p.GradlePropertiesFileName)
+		if !ok {
+			continue
+		}
 
-			err = os.Symlink(path, gradlePropertiesPath)
-			if err != nil {
-				return libcnb.Layer{}, fmt.Errorf("unable to create symlink for %s on retry\n%w", p.GradlePropertiesFileName, err)
-			}
-		} else if err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to symlink bound %s\n%w", p.GradlePropertiesFileName, err)
+		boundProperties, err := properties.LoadFile(path, properties.UTF8)
+		if err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to read bound %s file at %s\n%w", p.GradlePropertiesFileName, path, err)
 		}
-	} else if p.GradlePropertiesName == "gradle-wrapper-properties" {
-		file, err := os.ReadFile(path)
+		p.Logger.Debugf("applying these bound %s to default one: \n%s\n", p.GradlePropertiesName, boundProperties.String())
+		bound = append(bound, boundProperties)
+	}
+	if len(bound) == 0 {
+		return libcnb.Layer{}, nil
+	}
+
+	originalPropertiesFilePath := filepath.Join(p.GradlePropertiesHome, p.GradlePropertiesFileName)
+	if p.GradlePropertiesName == "gradle-wrapper-properties" {
+		mergedProperties, err := properties.LoadFiles([]string{originalPropertiesFilePath}, properties.UTF8, true)
 		if err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to read bound gradle-wrapper.properties file at %s\n%w", path, err)
+			return libcnb.Layer{}, fmt.Errorf("unable to read original gradle-wrapper.properties file at %s\n%w", originalPropertiesFilePath, err)
+		}
+		for _, boundProperties := range bound {
+			mergedProperties.Merge(boundProperties)
+		}
+
+		if err := os.MkdirAll(p.GradlePropertiesHome, 0755); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to create directory %s\n%w", p.GradlePropertiesHome, err)
 		}
-		p.Logger.Debugf("applying these bound gradle-wrapper-properties to default one: \n%s\n", string(file))
-		mergedProperties := properties.MustLoadFiles([]string{originalPropertiesFilePath, path}, properties.UTF8, true)
 		propertiesFile, err := os.Create(originalPropertiesFilePath)
 		if err != nil {
 			return libcnb.Layer{}, fmt.Errorf("unable to create/update original gradle-wrapper.properties file at %s\n%w", originalPropertiesFilePath, err)
 		}
+		defer propertiesFile.Close()
+
 		_, err = mergedProperties.Write(propertiesFile, properties.UTF8)
 		if err != nil {
 			return libcnb.Layer{}, fmt.Errorf("unable to merge gradle-wrapper.properties files.\n%w", err)
@@ -61,6 +82,216 @@ func (p PropertiesFile) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
 	return layer, nil
 }
 
+// contributeGradleProperties writes the merged gradle.properties to the layer. $GRADLE_USER_HOME is backed by the
+// cache layer, so the file is only linked into $GRADLE_USER_HOME by the Executor while Gradle runs.
+func (p PropertiesFile) contributeGradleProperties(layer libcnb.Layer) (libcnb.Layer, error) {
+	originalPropertiesFilePath := filepath.Join(p.GradlePropertiesHome, p.GradlePropertiesFileName)
+
+	if ok, err := sherpa.SymlinkExists(originalPropertiesFilePath); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to check for symlink %s\n%w", originalPropertiesFilePath, err)
+	} else if ok {
+		p.Logger.Debugf("removing %s left behind by a previous build", originalPropertiesFilePath)
+		if err := os.Remove(originalPropertiesFilePath); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to remove old symlink for %s\n%w", p.GradlePropertiesFileName, err)
+		}
+	}
+
+	values := map[string]string{}
+	sources := map[string]string{}
+	merge := func(source string, m map[string]string) {
+		for k, v := range m {
+			values[k] = v
+			sources[k] = source
+		}
+	}
+
+	project := map[string]string{}
+	if p.ProjectGradleProperties != "" {
+		if ok, err := sherpa.FileExists(p.ProjectGradleProperties); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to check for %s\n%w", p.ProjectGradleProperties, err)
+		} else if ok {
+			if project, err = loadProperties(p.ProjectGradleProperties); err != nil {
+				return libcnb.Layer{}, fmt.Errorf("unable to read %s\n%w", p.ProjectGradleProperties, err)
+			}
+		}
+	}
+
+	defaults := map[string]string{}
+	for k, v := range p.Defaults {
+		if _, ok := project[k]; ok {
+			p.Logger.Debugf("not setting %s as it is set by %s", k, p.ProjectGradleProperties)
+			continue
+		}
+		defaults[k] = v
+	}
+	merge("buildpack default", defaults)
+
+	if ok, err := sherpa.FileExists(originalPropertiesFilePath); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to check for %s\n%w", originalPropertiesFilePath, err)
+	} else if ok {
+		cached, err := loadProperties(originalPropertiesFilePath)
+		if err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to read %s\n%w", originalPropertiesFilePath, err)
+		}
+		merge(originalPropertiesFilePath, cached)
+	}
+
+	for _, binding := range p.Bindings {
+		if path, ok := binding.SecretFilePath(p.GradlePropertiesFileName); ok {
+			bound, err := loadProperties(path)
+			if err != nil {
+				return libcnb.Layer{}, fmt.Errorf("unable to read bound %s file at %s\n%w", p.GradlePropertiesFileName, path, err)
+			}
+			merge(fmt.Sprintf("binding %s", binding.Name), bound)
+		}
+	}
+
+	merge("BP_GRADLE_PROPERTY_*", p.Overrides)
+
+	jvmArgs, ok := values["org.gradle.jvmargs"]
+	var jvmArgsSources []string
+	if ok {
+		jvmArgsSources = append(jvmArgsSources, sources["org.gradle.jvmargs"])
+	} else if jvmArgs, ok = project["org.gradle.jvmargs"]; ok {
+		jvmArgsSources = append(jvmArgsSources, p.ProjectGradleProperties)
+	}
+	if overridden := OverrideJVMArgs(jvmArgs, p.JVMArgOverrides...); overridden != jvmArgs {
+		jvmArgs = overridden
+		jvmArgsSources = append(jvmArgsSources, "BP_GRADLE_JVM_ARGS")
+	}
+	if appended := AppendJVMArgs(jvmArgs, p.JVMArgs...); appended != jvmArgs {
+		jvmArgs = appended
+		jvmArgsSources = append(jvmArgsSources, "buildpack default")
+	}
+	if jvmArgs != values["org.gradle.jvmargs"] {
+		values["org.gradle.jvmargs"] = jvmArgs
+		sources["org.gradle.jvmargs"] = strings.Join(jvmArgsSources, " and ")
+	}
+
+	if len(values) == 0 {
+		return layer, nil
+	}
+
+	merged := properties.NewProperties()
+	merged.DisableExpansion = true
+	for _, k := range sortedKeys(values) {
+		p.Logger.Bodyf("Setting %s from %s", k, sources[k])
+		if _, _, err := merged.Set(k, values[k]); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to set %s\n%w", k, err)
+		}
+	}
+
+	if err := os.MkdirAll(layer.Path, 0755); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to create directory %s\n%w", layer.Path, err)
+	}
+
+	file := filepath.Join(layer.Path, p.GradlePropertiesFileName)
+	out, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to open %s\n%w", file, err)
+	}
+	defer out.Close()
+
+	if _, err := merged.Write(out, properties.UTF8); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to write %s\n%w", file, err)
+	}
+
+	return layer, nil
+}
+
 func (p PropertiesFile) Name() string {
 	return p.GradlePropertiesName
 }
+
+// PropertyOverrides returns the Gradle properties set by BP_GRADLE_PROPERTY_<NAME>=<key>=<value> environment
+// variables in environ.
+func PropertyOverrides(environ []string) (map[string]string, error) {
+	overrides := map[string]string{}
+
+	for _, e := range environ {
+		name, value, _ := strings.Cut(e, "=")
+		if !strings.HasPrefix(name, "BP_GRADLE_PROPERTY_") {
+			continue
+		}
+
+		k, v, ok := strings.Cut(value, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("invalid %s, expected a value of the form <key>=<value>", name)
+		}
+		overrides[strings.TrimSpace(k)] = v
+	}
+
+	return overrides, nil
+}
+
+// AppendJVMArgs appends args to the space separated jvmArgs, skipping those that jvmArgs already sets, e.g. -Xmx1g is
+// not appended to -Xmx512m and -Da=1 is not appended to -Da=2.
+func AppendJVMArgs(jvmArgs string, args ...string) string {
+	present := map[string]bool{}
+	for _, arg := range strings.Fields(jvmArgs) {
+		present[jvmArgName(arg)] = true
+	}
+
+	result := strings.Fields(jvmArgs)
+	for _, arg := range args {
+		if name := jvmArgName(arg); !present[name] {
+			result = append(result, arg)
+			present[name] = true
+		}
+	}
+
+	if len(result) == len(strings.Fields(jvmArgs)) {
+		return jvmArgs
+	}
+	return strings.Join(result, " ")
+}
+
+// OverrideJVMArgs replaces the arguments of the space separated jvmArgs that args set and appends the others.
+func OverrideJVMArgs(jvmArgs string, args ...string) string {
+	if len(args) == 0 {
+		return jvmArgs
+	}
+
+	overridden := map[string]bool{}
+	for _, arg := range args {
+		overridden[jvmArgName(arg)] = true
+	}
+
+	var result []string
+	for _, arg := range strings.Fields(jvmArgs) {
+		if !overridden[jvmArgName(arg)] {
+			result = append(result, arg)
+		}
+	}
+
+	return strings.Join(append(result, args...), " ")
+}
+
+// jvmArgName returns the part of a JVM argument that identifies what it sets.
+func jvmArgName(arg string) string {
+	switch {
+	case strings.HasPrefix(arg, "-D"):
+		name, _, _ := strings.Cut(arg, "=")
+		return name
+	case strings.HasPrefix(arg, "-XX:+"), strings.HasPrefix(arg, "-XX:-"):
+		return "-XX:" + arg[len("-XX:+"):]
+	case strings.HasPrefix(arg, "-XX:"):
+		name, _, _ := strings.Cut(arg, "=")
+		return name
+	case strings.HasPrefix(arg, "-Xmx"), strings.HasPrefix(arg, "-Xms"), strings.HasPrefix(arg, "-Xss"):
+		return arg[:len("-Xmx")]
+	default:
+		return arg
+	}
+}
+
+// loadProperties reads a properties file as is, without expanding ${...} references which Gradle does not support.
+func loadProperties(path string) (map[string]string, error) {
+	l := properties.Loader{Encoding: properties.UTF8, DisableExpansion: true}
+	p, err := l.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Map(), nil
+}
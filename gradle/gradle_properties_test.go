@@ -1,7 +1,10 @@
 package gradle_test
 
 import (
+	"bytes"
+
 	"github.com/magiconair/properties"
+	"github.com/paketo-buildpacks/libpak/bard"
 	"github.com/paketo-buildpacks/libpak/sherpa"
 	"os"
 	"path/filepath"
@@ -78,7 +81,7 @@ func testGradleProperties(t *testing.T, context spec.G, it spec.S) {
 				{
 					Name:   "some-gradle",
 					Type:   "gradle",
-					Secret: map[string]string{"gradle.properties": "gradle-properties-content"},
+					Secret: map[string]string{"gradle.properties": "bound.key=bound-value\nshared.key=bound-value\n"},
 					Path:   bindingPath,
 				},
 			}
@@ -87,7 +90,7 @@ func testGradleProperties(t *testing.T, context spec.G, it spec.S) {
 			Expect(ok).To(BeTrue())
 			Expect(os.WriteFile(
 				gradleSrcPropsPath,
-				[]byte("gradle-properties-content"),
+				[]byte("bound.key=bound-value\nshared.key=bound-value\n"),
 				0644,
 			)).To(Succeed())
 
@@ -98,55 +101,263 @@ func testGradleProperties(t *testing.T, context spec.G, it spec.S) {
 			Expect(err).NotTo(HaveOccurred())
 
 			gradleProps = gradle.PropertiesFile{
-				Binding:                  ctx.Platform.Bindings[0],
+				Bindings:                 ctx.Platform.Bindings,
 				GradlePropertiesHome:     gradleHome,
 				GradlePropertiesFileName: "gradle.properties",
 				GradlePropertiesName:     "gradle-properties",
 			}
 		})
 
-		it("creates a symlink for gradle.properties under $GRADLE_USER_HOME", func() {
+		it("writes gradle.properties into the layer and not under $GRADLE_USER_HOME", func() {
 			layer, err := gradleProps.Contribute(gradleLayer)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(layer).To(Equal(gradleLayer))
 
-			info, err := os.Lstat(gradleTargetPropsPath)
+			merged := properties.MustLoadFile(filepath.Join(layer.Path, "gradle.properties"), properties.UTF8)
+			Expect(merged.Map()).To(Equal(map[string]string{"bound.key": "bound-value", "shared.key": "bound-value"}))
+
+			_, err = os.Lstat(gradleTargetPropsPath)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		it("removes a symlink for gradle.properties under $GRADLE_USER_HOME left behind by a previous build", func() {
+			Expect(os.Symlink(filepath.Join(bindingPath, "gradle.properties"), gradleTargetPropsPath)).To(Succeed())
+
+			_, err := gradleProps.Contribute(gradleLayer)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(info.Mode()&os.ModeSymlink != 0).To(BeTrue()) // is symlink bit set
 
-			target, err := os.Readlink(gradleTargetPropsPath)
+			_, err = os.Lstat(gradleTargetPropsPath)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		it("merges defaults, the cached file, the binding and overrides in order", func() {
+			Expect(os.WriteFile(gradleTargetPropsPath, []byte("cached.key=cached-value\nshared.key=cached-value\noverridden.key=cached-value\n"), 0644)).To(Succeed())
+			gradleProps.Defaults = map[string]string{"default.key": "default-value", "cached.key": "default-value"}
+			gradleProps.Overrides = map[string]string{"overridden.key": "override-value", "shared.key": "override-value"}
+
+			layer, err := gradleProps.Contribute(gradleLayer)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(target).To(Equal(filepath.Join(bindingPath, "gradle.properties")))
 
-			data, err := os.ReadFile(gradleTargetPropsPath)
-			Expect(err).ToNot(HaveOccurred())
-			Expect(string(data)).To(Equal("gradle-properties-content"))
+			merged := properties.MustLoadFile(filepath.Join(layer.Path, "gradle.properties"), properties.UTF8)
+			Expect(merged.Map()).To(Equal(map[string]string{
+				"bound.key":      "bound-value",
+				"cached.key":     "cached-value",
+				"default.key":    "default-value",
+				"overridden.key": "override-value",
+				"shared.key":     "override-value",
+			}))
+
+			Expect(os.ReadFile(gradleTargetPropsPath)).To(ContainSubstring("cached.key=cached-value"))
 		})
 
-		it("recreates symlink for gradle.properties under $GRADLE_USER_HOME", func() {
-			Expect(os.MkdirAll(filepath.Dir(gradleTargetPropsPath), 0755)).ToNot(HaveOccurred())
-			err := os.Symlink("/dev/null", gradleTargetPropsPath)
+		it("does not expand references", func() {
+			gradleProps.Overrides = map[string]string{"reference.key": "${bound.key}"}
+
+			layer, err := gradleProps.Contribute(gradleLayer)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.ReadFile(filepath.Join(layer.Path, "gradle.properties"))).To(ContainSubstring("reference.key = ${bound.key}"))
+		})
+
+		it("logs where keys came from without their values", func() {
+			buf := &bytes.Buffer{}
+			gradleProps.Logger = bard.NewLogger(buf)
+			gradleProps.Overrides = map[string]string{"shared.key": "override-value"}
+
+			_, err := gradleProps.Contribute(gradleLayer)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buf.String()).To(ContainSubstring("Setting bound.key from binding some-gradle"))
+			Expect(buf.String()).To(ContainSubstring("Setting shared.key from BP_GRADLE_PROPERTY_*"))
+			Expect(buf.String()).NotTo(ContainSubstring("value"))
+		})
+	})
+
+	context("multiple gradle properties bindings are present", func() {
+		it.Before(func() {
+			var err error
+
+			for _, b := range []struct{ name, content string }{
+				{"a-gradle", "a.key=a-value\nshared.key=a-value\n"},
+				{"b-gradle", "b.key=b-value\nshared.key=b-value\n"},
+			} {
+				binding := libcnb.Binding{
+					Name:   b.name,
+					Type:   "gradle",
+					Secret: map[string]string{"gradle.properties": b.content},
+					Path:   filepath.Join(ctx.Platform.Path, "bindings", b.name),
+				}
+				Expect(os.MkdirAll(binding.Path, 0755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(binding.Path, "gradle.properties"), []byte(b.content), 0644)).To(Succeed())
+				ctx.Platform.Bindings = append(ctx.Platform.Bindings, binding)
+			}
+
+			gradleLayer, err = ctx.Layers.Layer("gradle-properties")
 			Expect(err).NotTo(HaveOccurred())
-			Expect(gradleTargetPropsPath).To(BeAnExistingFile())
 
+			gradleProps = gradle.PropertiesFile{
+				Bindings:                 ctx.Platform.Bindings,
+				GradlePropertiesHome:     gradleHome,
+				GradlePropertiesFileName: "gradle.properties",
+				GradlePropertiesName:     "gradle-properties",
+			}
+		})
+
+		it.After(func() {
+			ctx.Platform.Bindings = nil
+		})
+
+		it("merges the bindings with later bindings taking precedence", func() {
 			layer, err := gradleProps.Contribute(gradleLayer)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(layer).To(Equal(gradleLayer))
 
-			info, err := os.Lstat(gradleTargetPropsPath)
+			merged := properties.MustLoadFile(filepath.Join(layer.Path, "gradle.properties"), properties.UTF8)
+			Expect(merged.Map()).To(Equal(map[string]string{
+				"a.key":      "a-value",
+				"b.key":      "b-value",
+				"shared.key": "b-value",
+			}))
+		})
+	})
+
+	context("only overrides are present", func() {
+		it.Before(func() {
+			var err error
+
+			gradleLayer, err = ctx.Layers.Layer("gradle-properties")
 			Expect(err).NotTo(HaveOccurred())
-			Expect(info.Mode()&os.ModeSymlink != 0).To(BeTrue()) // is symlink bit set
 
-			target, err := os.Readlink(gradleTargetPropsPath)
+			gradleProps = gradle.PropertiesFile{
+				GradlePropertiesHome:     gradleHome,
+				GradlePropertiesFileName: "gradle.properties",
+				GradlePropertiesName:     "gradle-properties",
+				Overrides:                map[string]string{"some.key": "some-value"},
+			}
+		})
+
+		it("writes gradle.properties into the layer", func() {
+			layer, err := gradleProps.Contribute(gradleLayer)
 			Expect(err).NotTo(HaveOccurred())
-			// symlink should point to our binding, not /dev/null
-			Expect(target).To(Equal(filepath.Join(bindingPath, "gradle.properties")))
 
-			data, err := os.ReadFile(gradleTargetPropsPath)
-			Expect(err).ToNot(HaveOccurred())
-			Expect(string(data)).To(Equal("gradle-properties-content"))
+			merged := properties.MustLoadFile(filepath.Join(layer.Path, "gradle.properties"), properties.UTF8)
+			Expect(merged.Map()).To(Equal(map[string]string{"some.key": "some-value"}))
 		})
+	})
+
+	context("JVMArgs are set", func() {
+		it.Before(func() {
+			var err error
 
+			gradleLayer, err = ctx.Layers.Layer("gradle-properties")
+			Expect(err).NotTo(HaveOccurred())
+
+			gradleProps = gradle.PropertiesFile{
+				GradlePropertiesHome:     gradleHome,
+				GradlePropertiesFileName: "gradle.properties",
+				GradlePropertiesName:     "gradle-properties",
+				JVMArgs:                  []string{"-Djavax.net.ssl.trustStore=/layers/truststore/truststore.p12", "-Xmx1g"},
+			}
+		})
+
+		it("sets org.gradle.jvmargs", func() {
+			layer, err := gradleProps.Contribute(gradleLayer)
+			Expect(err).NotTo(HaveOccurred())
+
+			merged := properties.MustLoadFile(filepath.Join(layer.Path, "gradle.properties"), properties.UTF8)
+			Expect(merged.MustGetString("org.gradle.jvmargs")).To(Equal("-Djavax.net.ssl.trustStore=/layers/truststore/truststore.p12 -Xmx1g"))
+		})
+
+		it("appends to org.gradle.jvmargs of overrides", func() {
+			gradleProps.Overrides = map[string]string{"org.gradle.jvmargs": "-Xmx2g -Dfile.encoding=UTF-8"}
+
+			layer, err := gradleProps.Contribute(gradleLayer)
+			Expect(err).NotTo(HaveOccurred())
+
+			merged := properties.MustLoadFile(filepath.Join(layer.Path, "gradle.properties"), properties.UTF8)
+			Expect(merged.MustGetString("org.gradle.jvmargs")).To(Equal("-Xmx2g -Dfile.encoding=UTF-8 -Djavax.net.ssl.trustStore=/layers/truststore/truststore.p12"))
+		})
+
+		it("replaces arguments of org.gradle.jvmargs with JVMArgOverrides", func() {
+			gradleProps.Overrides = map[string]string{"org.gradle.jvmargs": "-Xmx2g -Dfile.encoding=UTF-8"}
+			gradleProps.JVMArgOverrides = []string{"-Xmx3g", "-XX:+UseParallelGC"}
+
+			layer, err := gradleProps.Contribute(gradleLayer)
+			Expect(err).NotTo(HaveOccurred())
+
+			merged := properties.MustLoadFile(filepath.Join(layer.Path, "gradle.properties"), properties.UTF8)
+			Expect(merged.MustGetString("org.gradle.jvmargs")).To(Equal("-Dfile.encoding=UTF-8 -Xmx3g -XX:+UseParallelGC -Djavax.net.ssl.trustStore=/layers/truststore/truststore.p12"))
+		})
+
+		context("the project has gradle.properties", func() {
+			it.Before(func() {
+				gradleProps.ProjectGradleProperties = filepath.Join(ctx.Application.Path, "gradle.properties")
+				Expect(os.WriteFile(gradleProps.ProjectGradleProperties,
+					[]byte("org.gradle.jvmargs=-Xmx4g -Dfile.encoding=UTF-8\norg.gradle.workers.max=2\n"), 0644)).To(Succeed())
+			})
+
+			it("appends to org.gradle.jvmargs of the project", func() {
+				layer, err := gradleProps.Contribute(gradleLayer)
+				Expect(err).NotTo(HaveOccurred())
+
+				merged := properties.MustLoadFile(filepath.Join(layer.Path, "gradle.properties"), properties.UTF8)
+				Expect(merged.MustGetString("org.gradle.jvmargs")).To(Equal("-Xmx4g -Dfile.encoding=UTF-8 -Djavax.net.ssl.trustStore=/layers/truststore/truststore.p12"))
+			})
+
+			it("does not set defaults the project sets", func() {
+				gradleProps.Defaults = map[string]string{"org.gradle.workers.max": "8", "default.key": "default-value"}
+
+				layer, err := gradleProps.Contribute(gradleLayer)
+				Expect(err).NotTo(HaveOccurred())
+
+				merged := properties.MustLoadFile(filepath.Join(layer.Path, "gradle.properties"), properties.UTF8)
+				Expect(merged.Keys()).NotTo(ContainElement("org.gradle.workers.max"))
+				Expect(merged.MustGetString("default.key")).To(Equal("default-value"))
+			})
+		})
+	})
+
+	context("OverrideJVMArgs", func() {
+		it("replaces arguments that are set and appends the others", func() {
+			Expect(gradle.OverrideJVMArgs("", "-Xmx1g")).To(Equal("-Xmx1g"))
+			Expect(gradle.OverrideJVMArgs("-Xmx512m -Da=1 -XX:-UseG1GC", "-Xmx1g", "-XX:+UseG1GC", "-Db=3")).
+				To(Equal("-Da=1 -Xmx1g -XX:+UseG1GC -Db=3"))
+		})
+
+		it("does not change arguments without overrides", func() {
+			Expect(gradle.OverrideJVMArgs(" -Xmx1g  -Da=1")).To(Equal(" -Xmx1g  -Da=1"))
+		})
+	})
+
+	context("AppendJVMArgs", func() {
+		it("appends arguments that are not set", func() {
+			Expect(gradle.AppendJVMArgs("", "-Xmx1g")).To(Equal("-Xmx1g"))
+			Expect(gradle.AppendJVMArgs("-Xmx512m -Da=1", "-Xmx1g", "-Da=2", "-Db=3")).To(Equal("-Xmx512m -Da=1 -Db=3"))
+			Expect(gradle.AppendJVMArgs("-XX:MaxMetaspaceSize=256m -XX:-UseG1GC", "-XX:MaxMetaspaceSize=512m", "-XX:+UseG1GC")).
+				To(Equal("-XX:MaxMetaspaceSize=256m -XX:-UseG1GC"))
+		})
+
+		it("does not change arguments without additions", func() {
+			Expect(gradle.AppendJVMArgs(" -Xmx1g  -Da=1", "-Xmx2g")).To(Equal(" -Xmx1g  -Da=1"))
+		})
+	})
+
+	context("PropertyOverrides", func() {
+		it("parses BP_GRADLE_PROPERTY_* variables", func() {
+			Expect(gradle.PropertyOverrides([]string{
+				"BP_GRADLE_PROPERTY_PROXY=systemProp.https.proxyHost=proxy.example.com",
+				"BP_GRADLE_PROPERTY_EMPTY=some.key=",
+				"BP_GRADLE_VERSION=8.5",
+				"OTHER=a=b",
+			})).To(Equal(map[string]string{
+				"systemProp.https.proxyHost": "proxy.example.com",
+				"some.key":                   "",
+			}))
+		})
+
+		it("fails without a key", func() {
+			_, err := gradle.PropertyOverrides([]string{"BP_GRADLE_PROPERTY_INVALID=secret"})
+			Expect(err).To(MatchError("invalid BP_GRADLE_PROPERTY_INVALID, expected a value of the form <key>=<value>"))
+		})
 	})
 
 	context("a gradle wrapper properties binding is present and contributes its content", func() {
@@ -185,13 +396,32 @@ func testGradleProperties(t *testing.T, context spec.G, it spec.S) {
 			Expect(err).NotTo(HaveOccurred())
 
 			gradleProps = gradle.PropertiesFile{
-				Binding:                  ctx.Platform.Bindings[0],
+				Bindings:                 ctx.Platform.Bindings,
 				GradlePropertiesHome:     gradleWrapperHome,
 				GradlePropertiesFileName: "gradle-wrapper.properties",
 				GradlePropertiesName:     "gradle-wrapper-properties",
 			}
 		})
 
+		it("creates gradle wrapper properties if the application has none", func() {
+			Expect(os.RemoveAll(gradleWrapperHome)).To(Succeed())
+
+			_, err := gradleProps.Contribute(gradleLayer)
+			Expect(err).NotTo(HaveOccurred())
+
+			finalProperties := properties.MustLoadFile(gradleWrapperTargetPropsPath, properties.UTF8)
+			Expect(finalProperties.Keys()).To(ConsistOf("distributionUrl", "networkTimeout"))
+			Expect(finalProperties.MustGetString("distributionUrl")).To(Equal("https://g.o/gradle-7.5-bin.zip"))
+		})
+
+		it("fails if the bound gradle wrapper properties cannot be read", func() {
+			gradleSrcPropsPath, _ := ctx.Platform.Bindings[0].SecretFilePath("gradle-wrapper.properties")
+			Expect(os.Remove(gradleSrcPropsPath)).To(Succeed())
+
+			_, err := gradleProps.Contribute(gradleLayer)
+			Expect(err).To(MatchError(ContainSubstring("unable to read bound gradle-wrapper.properties file at " + gradleSrcPropsPath)))
+		})
+
 		it("merges gradle wrapper properties files", func() {
 			layer, err := gradleProps.Contribute(gradleLayer)
 			Expect(err).NotTo(HaveOccurred())
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+// InitScript writes an init script generated by the buildpack to a build-only layer. Like the init scripts of gradle
+// bindings, it is linked into $GRADLE_USER_HOME/init.d by the Executor while Gradle runs.
+type InitScript struct {
+	Content  string
+	FileName string
+	Logger   bard.Logger
+}
+
+func (i InitScript) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	if err := os.MkdirAll(layer.Path, 0755); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to create directory %s\n%w", layer.Path, err)
+	}
+
+	file := filepath.Join(layer.Path, i.FileName)
+	i.Logger.Bodyf("Writing init script %s", i.FileName)
+	if err := os.WriteFile(file, []byte(i.Content), 0644); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to write %s\n%w", file, err)
+	}
+
+	return layer, nil
+}
+
+func (i InitScript) Name() string {
+	return strings.TrimSuffix(i.FileName, filepath.Ext(i.FileName))
+}
+
+// Path returns the path of the init script in the layers directory.
+func (i InitScript) Path(layersPath string) string {
+	return filepath.Join(layersPath, i.Name(), i.FileName)
+}
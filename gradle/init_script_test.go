@@ -0,0 +1,56 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/gradle/v7/gradle"
+)
+
+func testInitScript(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		ctx libcnb.BuildContext
+	)
+
+	it.Before(func() {
+		ctx.Layers.Path = t.TempDir()
+	})
+
+	it("writes the init script to the layer", func() {
+		script := gradle.InitScript{Content: "allprojects {}", FileName: "test-script.gradle"}
+		Expect(script.Name()).To(Equal("test-script"))
+
+		layer, err := ctx.Layers.Layer(script.Name())
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = script.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(script.Path(ctx.Layers.Path)).To(Equal(filepath.Join(layer.Path, "test-script.gradle")))
+		Expect(os.ReadFile(script.Path(ctx.Layers.Path))).To(Equal([]byte("allprojects {}")))
+		Expect(layer.LayerTypes).To(Equal(libcnb.LayerTypes{}))
+	})
+}
@@ -17,17 +17,47 @@
 package gradle_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	. "github.com/onsi/gomega"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 )
 
 func TestUnit(t *testing.T) {
 	suite := spec.New("gradle", spec.Report(report.Terminal{}))
+	suite("Bindings", testBindings)
 	suite("Build", testBuild)
+	suite("BuildCache", testBuildCache)
+	suite("Cache", testCache)
+	suite("CachePruner", testCachePruner)
+	suite("Container", testContainer)
 	suite("Detect", testDetect)
 	suite("Distribution", testDistribution)
+	suite("Executor", testExecutor)
+	suite("InitScript", testInitScript)
+	suite("JDKVersion", testJDKVersion)
 	suite("Properties", testGradleProperties)
+	suite("Proxy", testProxy)
+	suite("RepositoryAllowlist", testRepositoryAllowlist)
+	suite("RepositoryMirror", testRepositoryMirror)
+	suite("TestReports", testTestReports)
+	suite("Toolchain", testToolchain)
+	suite("Truststore", testTruststore)
+	suite("Wrapper", testWrapper)
+	suite("WrapperDistribution", testWrapperDistribution)
+	suite("WrapperValidation", testWrapperValidation)
 	suite.Run(t)
 }
+
+// writeFile writes content to path below dir, creating the parent directories.
+func writeFile(t *testing.T, dir string, path string, content string) {
+	t.Helper()
+	Expect := NewWithT(t).Expect
+
+	file := filepath.Join(dir, path)
+	Expect(os.MkdirAll(filepath.Dir(file), 0755)).To(Succeed())
+	Expect(os.WriteFile(file, []byte(content), 0644)).To(Succeed())
+}
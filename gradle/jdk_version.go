@@ -0,0 +1,227 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+var (
+	compatibilityPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?:sourceCompatibility|targetCompatibility|jvmTarget)\s*(?:=|\.set\s*\()\s*(?:JavaVersion\.VERSION_|JavaVersion\.toVersion\s*\(\s*|JvmTarget\.JVM_|JvmTarget\.fromTarget\s*\(\s*)?['"]?(?:1[._])?(\d+)`),
+		regexp.MustCompile(`release\s*(?:=|\.set\s*\()\s*(\d+)`),
+	}
+	catalogVersionKey = regexp.MustCompile(`^(?i)(java|jdk|jvm)([-_.]?(version|toolchain|target|language[-_.]?version))?$`)
+	javaVersion       = regexp.MustCompile(`(?:^|[^\d.])(?:1\.)?(\d+)`)
+)
+
+// JDKVersion is the Java feature version a project requires and the file, relative to the project, declaring it.
+type JDKVersion struct {
+	Source  string
+	Version int
+}
+
+// ResolveJDKVersion statically inspects the files of the project at projectDir for the Java version it requires, in
+// decreasing order of precedence:
+//
+//   - toolchains in build.gradle(.kts), i.e. JavaLanguageVersion.of(...) and jvmToolchain(...)
+//   - a java, jdk or jvm version in the [versions] of gradle/libs.versions.toml
+//   - the toolchainVersion of gradle/gradle-daemon-jvm.properties
+//   - sourceCompatibility, targetCompatibility, jvmTarget and release in build.gradle(.kts)
+//   - the java candidate of .sdkmanrc
+//   - the java tool of .tool-versions
+//
+// The highest version of a build script wins. As the version is only a hint, files that cannot be read or parsed are
+// logged and skipped. It returns false if none of the files declare a version.
+func ResolveJDKVersion(projectDir string, logger bard.Logger) (JDKVersion, bool) {
+	buildFiles := []string{"build.gradle", "build.gradle.kts"}
+
+	for _, resolve := range []func() (JDKVersion, bool, error){
+		func() (JDKVersion, bool, error) { return scriptVersion(projectDir, buildFiles, toolchainPatterns) },
+		func() (JDKVersion, bool, error) { return catalogVersion(projectDir) },
+		func() (JDKVersion, bool, error) { return daemonJVMVersion(projectDir) },
+		func() (JDKVersion, bool, error) { return scriptVersion(projectDir, buildFiles, compatibilityPatterns) },
+		func() (JDKVersion, bool, error) { return toolVersion(projectDir, ".sdkmanrc", "=") },
+		func() (JDKVersion, bool, error) { return toolVersion(projectDir, ".tool-versions", " ") },
+	} {
+		if v, ok, err := resolve(); err != nil {
+			logger.Bodyf("WARNING: ignoring a declared Java version:\n%s", err)
+		} else if ok {
+			return v, true
+		}
+	}
+
+	return JDKVersion{}, false
+}
+
+func scriptVersion(projectDir string, files []string, patterns []*regexp.Regexp) (JDKVersion, bool, error) {
+	var result JDKVersion
+
+	for _, file := range files {
+		b, ok, err := readOptional(filepath.Join(projectDir, file))
+		if err != nil {
+			return JDKVersion{}, false, err
+		} else if !ok {
+			continue
+		}
+
+		for _, pattern := range patterns {
+			for _, match := range pattern.FindAllStringSubmatch(stripComments(string(b)), -1) {
+				version, err := strconv.Atoi(match[1])
+				if err != nil {
+					return JDKVersion{}, false, fmt.Errorf("unable to parse Java version %s in %s\n%w", match[1], file, err)
+				}
+				if version > result.Version {
+					result = JDKVersion{Source: file, Version: version}
+				}
+			}
+		}
+	}
+
+	return result, result.Version > 0, nil
+}
+
+func catalogVersion(projectDir string) (JDKVersion, bool, error) {
+	file := filepath.Join("gradle", "libs.versions.toml")
+	b, ok, err := readOptional(filepath.Join(projectDir, file))
+	if err != nil || !ok {
+		return JDKVersion{}, false, err
+	}
+
+	var catalog struct {
+		Versions map[string]interface{} `toml:"versions"`
+	}
+	if err := toml.Unmarshal(b, &catalog); err != nil {
+		return JDKVersion{}, false, fmt.Errorf("unable to parse %s\n%w", file, err)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(catalog.Versions)) {
+		if !catalogVersionKey.MatchString(key) {
+			continue
+		}
+
+		var value string
+		switch v := catalog.Versions[key].(type) {
+		case string:
+			value = v
+		case int64:
+			value = strconv.FormatInt(v, 10)
+		case map[string]interface{}:
+			for _, constraint := range []string{"strictly", "require", "prefer"} {
+				if s, ok := v[constraint].(string); ok {
+					value = s
+					break
+				}
+			}
+		}
+
+		if version, ok := parseJavaVersion(value); ok {
+			return JDKVersion{Source: file, Version: version}, true, nil
+		}
+	}
+
+	return JDKVersion{}, false, nil
+}
+
+func daemonJVMVersion(projectDir string) (JDKVersion, bool, error) {
+	file := filepath.Join("gradle", "gradle-daemon-jvm.properties")
+	if ok, err := sherpa.FileExists(filepath.Join(projectDir, file)); err != nil {
+		return JDKVersion{}, false, fmt.Errorf("unable to check for %s\n%w", file, err)
+	} else if !ok {
+		return JDKVersion{}, false, nil
+	}
+
+	p, err := loadProperties(filepath.Join(projectDir, file))
+	if err != nil {
+		return JDKVersion{}, false, fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	if version, ok := parseJavaVersion(p["toolchainVersion"]); ok {
+		return JDKVersion{Source: file, Version: version}, true, nil
+	}
+	return JDKVersion{}, false, nil
+}
+
+// toolVersion reads the java entry of .sdkmanrc (java=21.0.2-tem) or .tool-versions (java temurin-21.0.2).
+func toolVersion(projectDir string, file string, separator string) (JDKVersion, bool, error) {
+	b, ok, err := readOptional(filepath.Join(projectDir, file))
+	if err != nil || !ok {
+		return JDKVersion{}, false, err
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(b)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, separator)
+		if !ok || strings.TrimSpace(name) != "java" {
+			continue
+		}
+
+		// .tool-versions may list fallback versions, the first one is preferred
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if version, ok := parseJavaVersion(fields[0]); ok {
+			return JDKVersion{Source: file, Version: version}, true, nil
+		}
+	}
+
+	return JDKVersion{}, false, nil
+}
+
+// parseJavaVersion returns the feature version of a Java version, optionally prefixed by a vendor, e.g. 8 for 1.8,
+// 21 for 21.0.2-tem and 17 for temurin-17.0.9+9.
+func parseJavaVersion(s string) (int, bool) {
+	match := javaVersion.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return 0, false
+	}
+
+	version, err := strconv.Atoi(match[1])
+	if err != nil || version == 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+func readOptional(path string) ([]byte, bool, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, fmt.Errorf("unable to read %s\n%w", path, err)
+	}
+	return b, true, nil
+}
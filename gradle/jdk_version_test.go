@@ -0,0 +1,121 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/gradle/v7/gradle"
+)
+
+func testJDKVersion(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		output     *bytes.Buffer
+		projectDir string
+	)
+
+	resolve := func() gradle.JDKVersion {
+		version, ok := gradle.ResolveJDKVersion(projectDir, bard.NewLogger(output))
+		Expect(ok).To(BeTrue())
+		return version
+	}
+
+	it.Before(func() {
+		output = &bytes.Buffer{}
+		projectDir = t.TempDir()
+	})
+
+	it("returns false without declarations", func() {
+		writeFile(t, projectDir, "build.gradle", "plugins { id 'java' }")
+
+		_, ok := gradle.ResolveJDKVersion(projectDir, bard.NewLogger(output))
+		Expect(ok).To(BeFalse())
+	})
+
+	it("reads toolchains of build scripts", func() {
+		writeFile(t, projectDir, "build.gradle.kts", "java {\n  sourceCompatibility = JavaVersion.VERSION_17\n  toolchain { languageVersion.set(JavaLanguageVersion.of(21)) }\n}\n")
+
+		Expect(resolve()).To(Equal(gradle.JDKVersion{Source: "build.gradle.kts", Version: 21}))
+	})
+
+	it("reads the version catalog", func() {
+		writeFile(t, projectDir, "build.gradle", "java { toolchain { languageVersion = JavaLanguageVersion.of(libs.versions.java.get()) } }")
+		writeFile(t, projectDir, "gradle/libs.versions.toml", "[versions]\nspring-boot = \"3.2.0\"\njava = \"21\"\n")
+
+		Expect(resolve()).To(Equal(gradle.JDKVersion{Source: filepath.Join("gradle", "libs.versions.toml"), Version: 21}))
+	})
+
+	it("reads rich versions of the version catalog", func() {
+		writeFile(t, projectDir, "gradle/libs.versions.toml", "[versions]\njdk = { strictly = \"17\" }\n")
+
+		Expect(resolve()).To(Equal(gradle.JDKVersion{Source: filepath.Join("gradle", "libs.versions.toml"), Version: 17}))
+	})
+
+	it("reads the daemon JVM criteria", func() {
+		writeFile(t, projectDir, "gradle/gradle-daemon-jvm.properties", "#This file is generated by updateDaemonJvm\ntoolchainVersion=21\n")
+		writeFile(t, projectDir, "build.gradle", "sourceCompatibility = '17'")
+
+		Expect(resolve()).To(Equal(gradle.JDKVersion{Source: filepath.Join("gradle", "gradle-daemon-jvm.properties"), Version: 21}))
+	})
+
+	it("reads compatibility declarations of build scripts", func() {
+		writeFile(t, projectDir, "build.gradle", "java {\n  sourceCompatibility = JavaVersion.VERSION_1_8\n  targetCompatibility = '11'\n}\n")
+
+		Expect(resolve()).To(Equal(gradle.JDKVersion{Source: "build.gradle", Version: 11}))
+	})
+
+	it("reads jvmTarget and release of build scripts", func() {
+		writeFile(t, projectDir, "build.gradle.kts", "kotlin { compilerOptions { jvmTarget.set(JvmTarget.JVM_17) } }\ntasks.withType<JavaCompile> { options.release = 17 }\n")
+
+		Expect(resolve()).To(Equal(gradle.JDKVersion{Source: "build.gradle.kts", Version: 17}))
+	})
+
+	it("reads .sdkmanrc", func() {
+		writeFile(t, projectDir, ".sdkmanrc", "# Enable auto-env through the sdkman_auto_env config\ngradle=8.5\njava=21.0.2-tem\n")
+
+		Expect(resolve()).To(Equal(gradle.JDKVersion{Source: ".sdkmanrc", Version: 21}))
+	})
+
+	it("reads .tool-versions", func() {
+		writeFile(t, projectDir, ".tool-versions", "gradle 8.5\njava temurin-17.0.9+9 temurin-21.0.1+12\n")
+
+		Expect(resolve()).To(Equal(gradle.JDKVersion{Source: ".tool-versions", Version: 17}))
+	})
+
+	it("reads Java 8 versions", func() {
+		writeFile(t, projectDir, ".tool-versions", "java adoptopenjdk-8.0.292+10\n")
+
+		Expect(resolve()).To(Equal(gradle.JDKVersion{Source: ".tool-versions", Version: 8}))
+	})
+
+	it("skips a version catalog that cannot be parsed", func() {
+		writeFile(t, projectDir, filepath.Join("gradle", "libs.versions.toml"), "[versions\njava = 21\n")
+		writeFile(t, projectDir, ".sdkmanrc", "java=17.0.9-tem\n")
+
+		Expect(resolve()).To(Equal(gradle.JDKVersion{Source: ".sdkmanrc", Version: 17}))
+		Expect(output.String()).To(ContainSubstring("WARNING: ignoring a declared Java version"))
+		Expect(output.String()).To(ContainSubstring("libs.versions.toml"))
+	})
+}
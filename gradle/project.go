@@ -0,0 +1,65 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak"
+)
+
+// ProjectPath returns BP_GRADLE_PROJECT_PATH, the directory of the Gradle build relative to the application, cleaned,
+// or . if it is not set. It must not be absolute or point outside the application.
+func ProjectPath(cr libpak.ConfigurationResolver) (string, error) {
+	s, _ := cr.Resolve("BP_GRADLE_PROJECT_PATH")
+	if strings.TrimSpace(s) == "" {
+		return ".", nil
+	}
+
+	path := filepath.Clean(s)
+	if filepath.IsAbs(path) || path == ".." || strings.HasPrefix(path, fmt.Sprintf("..%c", filepath.Separator)) {
+		return "", fmt.Errorf("invalid BP_GRADLE_PROJECT_PATH %s, must be a directory relative to the application", s)
+	}
+
+	return path, nil
+}
+
+// ProjectArtifactResolver returns a copy of cr whose default BP_GRADLE_BUILT_ARTIFACT patterns are relative to
+// projectPath and BP_GRADLE_BUILT_MODULE in it. Patterns set explicitly are unchanged and stay relative to the
+// application. Resolvers using it must not join BP_GRADLE_BUILT_MODULE again.
+func ProjectArtifactResolver(cr libpak.ConfigurationResolver, projectPath string) libpak.ConfigurationResolver {
+	module, _ := cr.Resolve("BP_GRADLE_BUILT_MODULE")
+
+	configurations := make([]libpak.BuildpackConfiguration, len(cr.Configurations))
+	copy(configurations, cr.Configurations)
+
+	for i, c := range configurations {
+		if c.Name != "BP_GRADLE_BUILT_ARTIFACT" {
+			continue
+		}
+
+		var patterns []string
+		for _, pattern := range strings.Fields(c.Default) {
+			patterns = append(patterns, filepath.Join(projectPath, module, pattern))
+		}
+		configurations[i].Default = strings.Join(patterns, " ")
+	}
+
+	return libpak.ConfigurationResolver{Configurations: configurations}
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ProxyProperties translates the HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables of environ, which Gradle ignores, to
+// the equivalent systemProp.* Gradle properties. Upper case variables take precedence over lower case ones.
+func ProxyProperties(environ []string) (map[string]string, error) {
+	env := map[string]string{}
+	for _, e := range environ {
+		if name, value, ok := strings.Cut(e, "="); ok {
+			env[name] = value
+		}
+	}
+	lookup := func(names ...string) (string, string) {
+		for _, name := range names {
+			if value := strings.TrimSpace(env[name]); value != "" {
+				return name, value
+			}
+		}
+		return "", ""
+	}
+
+	properties := map[string]string{}
+
+	for _, protocol := range []string{"http", "https"} {
+		name, value := lookup(strings.ToUpper(protocol)+"_PROXY", protocol+"_proxy")
+		if value == "" {
+			continue
+		}
+
+		if !strings.Contains(value, "://") {
+			value = fmt.Sprintf("http://%s", value)
+		}
+		u, err := url.Parse(value)
+		if err != nil || u.Hostname() == "" {
+			// the value is not included as it may contain credentials
+			return nil, fmt.Errorf("invalid %s, must be a URL of the form [<scheme>://][<user>:<password>@]<host>[:<port>]", name)
+		}
+
+		port := u.Port()
+		if port == "" {
+			port = "80"
+			if u.Scheme == "https" {
+				port = "443"
+			}
+		}
+
+		properties[fmt.Sprintf("systemProp.%s.proxyHost", protocol)] = u.Hostname()
+		properties[fmt.Sprintf("systemProp.%s.proxyPort", protocol)] = port
+		if u.User != nil {
+			properties[fmt.Sprintf("systemProp.%s.proxyUser", protocol)] = u.User.Username()
+			if password, ok := u.User.Password(); ok {
+				properties[fmt.Sprintf("systemProp.%s.proxyPassword", protocol)] = password
+			}
+		}
+	}
+
+	if _, value := lookup("NO_PROXY", "no_proxy"); value != "" {
+		var hosts []string
+		for _, entry := range strings.Split(value, ",") {
+			entry = strings.TrimSpace(entry)
+
+			// Java supports neither ports nor CIDR ranges in nonProxyHosts
+			if strings.Contains(entry, "/") {
+				continue
+			}
+			if strings.Count(entry, ":") == 1 {
+				entry, _, _ = strings.Cut(entry, ":")
+			}
+
+			if entry == "" {
+				continue
+			} else if strings.HasPrefix(entry, ".") {
+				entry = "*" + entry
+			}
+			hosts = append(hosts, entry)
+		}
+
+		if len(hosts) > 0 {
+			properties["systemProp.http.nonProxyHosts"] = strings.Join(hosts, "|")
+			properties["systemProp.https.nonProxyHosts"] = strings.Join(hosts, "|")
+		}
+	}
+
+	return properties, nil
+}
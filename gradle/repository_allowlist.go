@@ -0,0 +1,266 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak"
+)
+
+// RepositoryAllowlistScript fails the build when a Maven or Ivy repository of a project, buildscript or the settings
+// is about to be used that is not allowed by the entries passed in the BPI_GRADLE_ALLOWED_REPOSITORIES environment
+// variable. Repositories are verified when they are used, or for the settings once all projects are loaded, rather than
+// when they are declared, so that repositories rewritten by the repository mirror init script are verified with their
+// final URL.
+const RepositoryAllowlistScript = `// Generated by the Paketo Gradle Buildpack
+import org.gradle.util.GradleVersion
+
+// the entries are normalized by the buildpack to "<scheme> <host> <port> <path>", * matching any scheme or port
+def allowedRepositories = System.getenv('BPI_GRADLE_ALLOWED_REPOSITORIES').tokenize(',').collect { it.tokenize(' ') }
+
+def gradlePluginPortal = 'https://plugins.gradle.org/m2/'
+
+def isAllowed = { URI uri ->
+    // local repositories such as mavenLocal() are not resolved from a host
+    if (uri == null || uri.scheme == 'file') {
+        return true
+    }
+    if (uri.host == null) {
+        return false
+    }
+
+    def scheme = uri.scheme.toLowerCase()
+    def host = uri.host.toLowerCase()
+    def port = uri.port != -1 ? uri.port : (scheme == 'https' ? 443 : scheme == 'http' ? 80 : -1)
+    def path = uri.rawPath ?: ''
+    if (!path.endsWith('/')) {
+        path += '/'
+    }
+
+    allowedRepositories.any { entry ->
+        def (entryScheme, entryHost, entryPort, entryPath) = entry
+        (entryScheme == '*' || entryScheme == scheme) &&
+            (entryHost.startsWith('*.') ? host.endsWith(entryHost.substring(1)) : host == entryHost) &&
+            (entryPort == '*' || entryPort == port.toString()) &&
+            path.startsWith(entryPath)
+    }
+}
+
+def verify = { String owner, RepositoryHandler repositories ->
+    repositories.each { repository ->
+        def uris = []
+        if (repository instanceof MavenArtifactRepository) {
+            uris.add(repository.url)
+            uris.addAll(repository.artifactUrls)
+        } else if (repository instanceof IvyArtifactRepository) {
+            uris.add(repository.url)
+        }
+
+        uris.each { uri ->
+            if (!isAllowed(uri)) {
+                throw new GradleException("Repository ${repository.name} (${uri}) of ${owner} is not allowed by BP_GRADLE_ALLOWED_REPOSITORIES")
+            }
+        }
+    }
+}
+
+// init scripts are applied in alphabetical order, so the settings are verified once the projects are loaded rather than
+// when they are evaluated, after the repository mirror init script has rewritten them in its settingsEvaluated hook
+def evaluatedSettings = null
+settingsEvaluated { settings ->
+    evaluatedSettings = settings
+}
+
+projectsLoaded {
+    verify('settings pluginManagement', evaluatedSettings.pluginManagement.repositories)
+    // without repositories, plugins are resolved from the Gradle Plugin Portal
+    if (evaluatedSettings.pluginManagement.repositories.isEmpty() && !isAllowed(new URI(gradlePluginPortal))) {
+        throw new GradleException("Gradle Plugin Portal (${gradlePluginPortal}), used as settings pluginManagement declares no repositories, is not allowed by BP_GRADLE_ALLOWED_REPOSITORIES")
+    }
+    if (GradleVersion.current() >= GradleVersion.version('6.8')) {
+        verify('settings dependencyResolutionManagement', evaluatedSettings.dependencyResolutionManagement.repositories)
+    }
+}
+
+allprojects { project ->
+    buildscript.configurations.all {
+        incoming.beforeResolve {
+            verify("buildscript of project ${project.path}", project.buildscript.repositories)
+        }
+    }
+    configurations.all {
+        incoming.beforeResolve {
+            verify("project ${project.path}", project.repositories)
+        }
+    }
+}
+`
+
+// RepositoryAllowlist is the configuration of the repository allowlist init script.
+type RepositoryAllowlist struct {
+	Entries []string
+}
+
+// allowedRepository is an entry of the allowlist, normalized so that the init script only has to compare the parts of
+// a repository URL. An empty Scheme or Port matches any, Path always ends with / so that it matches whole segments.
+type allowedRepository struct {
+	Scheme string
+	Host   string
+	Port   string
+	Path   string
+}
+
+// NewRepositoryAllowlist resolves BP_GRADLE_ALLOWED_REPOSITORIES, a comma or whitespace separated list of hosts, host
+// wildcards such as *.example.com, both optionally with a port, and URL prefixes. It returns false if no allowlist is
+// configured.
+func NewRepositoryAllowlist(cr libpak.ConfigurationResolver) (RepositoryAllowlist, bool, error) {
+	s, _ := cr.Resolve("BP_GRADLE_ALLOWED_REPOSITORIES")
+
+	entries := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+	if len(entries) == 0 {
+		return RepositoryAllowlist{}, false, nil
+	}
+
+	for _, entry := range entries {
+		if _, err := parseAllowedRepository(entry); err != nil {
+			return RepositoryAllowlist{}, false, err
+		}
+	}
+
+	return RepositoryAllowlist{Entries: entries}, true, nil
+}
+
+func parseAllowedRepository(entry string) (allowedRepository, error) {
+	invalid := fmt.Errorf("invalid BP_GRADLE_ALLOWED_REPOSITORIES entry %s, must be a host or an absolute URL", entry)
+
+	if strings.Contains(entry, "://") {
+		u, err := url.Parse(entry)
+		if err != nil || u.Hostname() == "" || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+			return allowedRepository{}, invalid
+		}
+
+		r := allowedRepository{
+			Scheme: strings.ToLower(u.Scheme),
+			Host:   strings.ToLower(u.Hostname()),
+			Port:   u.Port(),
+			Path:   u.EscapedPath(),
+		}
+		if r.Port == "" {
+			r.Port = defaultPort(r.Scheme)
+		}
+		if !strings.HasSuffix(r.Path, "/") {
+			r.Path += "/"
+		}
+		return r, nil
+	}
+
+	wildcard := strings.HasPrefix(entry, "*.")
+	u, err := url.Parse("//" + strings.TrimPrefix(entry, "*."))
+	if err != nil || u.Hostname() == "" || u.User != nil || u.Path != "" || u.RawQuery != "" || u.Fragment != "" ||
+		strings.Contains(u.Host, "*") {
+		return allowedRepository{}, invalid
+	}
+
+	r := allowedRepository{Host: strings.ToLower(u.Hostname()), Port: u.Port(), Path: "/"}
+	if wildcard {
+		r.Host = "*." + r.Host
+	}
+	return r, nil
+}
+
+func defaultPort(scheme string) string {
+	switch scheme {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	default:
+		return ""
+	}
+}
+
+// Allows returns whether the repository at uri is allowed, using the same rules as isAllowed of
+// RepositoryAllowlistScript: the scheme, host and port must match exactly, a wildcard host matches its subdomains and a
+// URL prefix matches whole path segments.
+func (r RepositoryAllowlist) Allows(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "file" {
+		return true
+	}
+	if u.Hostname() == "" {
+		return false
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port == "" {
+		port = defaultPort(scheme)
+	}
+	path := u.EscapedPath()
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+
+	for _, entry := range r.Entries {
+		a, err := parseAllowedRepository(entry)
+		if err != nil {
+			continue
+		}
+
+		hostMatches := host == a.Host
+		if strings.HasPrefix(a.Host, "*.") {
+			hostMatches = strings.HasSuffix(host, a.Host[1:])
+		}
+
+		if (a.Scheme == "" || a.Scheme == scheme) && hostMatches && (a.Port == "" || a.Port == port) &&
+			strings.HasPrefix(path, a.Path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Environment returns the environment variables read by RepositoryAllowlistScript.
+func (r RepositoryAllowlist) Environment() map[string]string {
+	var normalized []string
+	for _, entry := range r.Entries {
+		a, err := parseAllowedRepository(entry)
+		if err != nil {
+			continue
+		}
+		normalized = append(normalized, strings.Join([]string{orAny(a.Scheme), a.Host, orAny(a.Port), a.Path}, " "))
+	}
+
+	return map[string]string{"BPI_GRADLE_ALLOWED_REPOSITORIES": strings.Join(normalized, ",")}
+}
+
+func orAny(s string) string {
+	if s == "" {
+		return "*"
+	}
+	return s
+}
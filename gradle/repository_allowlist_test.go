@@ -0,0 +1,151 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/gradle/v7/gradle"
+)
+
+func testRepositoryAllowlist(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cr libpak.ConfigurationResolver
+	)
+
+	it("is not configured without BP_GRADLE_ALLOWED_REPOSITORIES", func() {
+		_, ok, err := gradle.NewRepositoryAllowlist(cr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+
+	it("parses hosts, host wildcards and URL prefixes", func() {
+		t.Setenv("BP_GRADLE_ALLOWED_REPOSITORIES", "nexus.example.com, *.internal.example.com\nhttps://repo.example.com/releases/")
+
+		allowlist, ok, err := gradle.NewRepositoryAllowlist(cr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+
+		Expect(allowlist.Entries).To(Equal([]string{"nexus.example.com", "*.internal.example.com", "https://repo.example.com/releases/"}))
+		Expect(allowlist.Environment()).To(Equal(map[string]string{
+			"BPI_GRADLE_ALLOWED_REPOSITORIES": "* nexus.example.com * /,* *.internal.example.com * /,https repo.example.com 443 /releases/",
+		}))
+	})
+
+	context("Allows", func() {
+		allows := func(entries string, uri string) bool {
+			t.Setenv("BP_GRADLE_ALLOWED_REPOSITORIES", entries)
+
+			allowlist, _, err := gradle.NewRepositoryAllowlist(cr)
+			Expect(err).NotTo(HaveOccurred())
+			return allowlist.Allows(uri)
+		}
+
+		it("matches hosts on any scheme and port", func() {
+			Expect(allows("nexus.example.com", "https://nexus.example.com/repository/maven-public/")).To(BeTrue())
+			Expect(allows("nexus.example.com", "http://NEXUS.example.com:8081/")).To(BeTrue())
+			Expect(allows("nexus.example.com", "https://nexus.example.com.attacker.io/")).To(BeFalse())
+			Expect(allows("nexus.example.com", "https://repo.example.com/")).To(BeFalse())
+		})
+
+		it("matches hosts with a port on that port only", func() {
+			Expect(allows("nexus.example.com:8443", "https://nexus.example.com:8443/repository/")).To(BeTrue())
+			Expect(allows("nexus.example.com:443", "https://nexus.example.com/repository/")).To(BeTrue())
+			Expect(allows("nexus.example.com:8443", "https://nexus.example.com/repository/")).To(BeFalse())
+		})
+
+		it("matches host wildcards on subdomains only", func() {
+			Expect(allows("*.example.com", "https://nexus.example.com/")).To(BeTrue())
+			Expect(allows("*.example.com", "https://a.b.example.com/")).To(BeTrue())
+			Expect(allows("*.example.com", "https://example.com/")).To(BeFalse())
+			Expect(allows("*.example.com", "https://attacker-example.com/")).To(BeFalse())
+			Expect(allows("*.example.com", "https://example.com.attacker.io/")).To(BeFalse())
+			Expect(allows("*.example.com:8443", "https://nexus.example.com/")).To(BeFalse())
+		})
+
+		it("matches URL prefixes by scheme, host, port and whole path segments", func() {
+			Expect(allows("https://nexus.corp", "https://nexus.corp/repository/")).To(BeTrue())
+			Expect(allows("https://nexus.corp", "https://nexus.corp:443/repository/")).To(BeTrue())
+			Expect(allows("https://nexus.corp", "https://nexus.corp.attacker.io/")).To(BeFalse())
+			Expect(allows("https://nexus.corp", "http://nexus.corp/")).To(BeFalse())
+			Expect(allows("https://nexus.corp", "https://nexus.corp:8443/")).To(BeFalse())
+
+			Expect(allows("https://repo/releases", "https://repo/releases")).To(BeTrue())
+			Expect(allows("https://repo/releases", "https://repo/releases/com/example/")).To(BeTrue())
+			Expect(allows("https://repo/releases/", "https://repo/releases")).To(BeTrue())
+			Expect(allows("https://repo/releases", "https://repo/releases-evil/")).To(BeFalse())
+			Expect(allows("https://repo/releases", "https://repo/")).To(BeFalse())
+		})
+
+		it("allows local repositories", func() {
+			Expect(allows("nexus.example.com", "file:/home/cnb/.m2/repository/")).To(BeTrue())
+		})
+	})
+
+	it("fails with a path without a scheme", func() {
+		t.Setenv("BP_GRADLE_ALLOWED_REPOSITORIES", "nexus.example.com/releases")
+
+		_, _, err := gradle.NewRepositoryAllowlist(cr)
+		Expect(err).To(MatchError("invalid BP_GRADLE_ALLOWED_REPOSITORIES entry nexus.example.com/releases, must be a host or an absolute URL"))
+	})
+
+	it("fails with invalid hosts and URLs", func() {
+		for _, entry := range []string{"nexus.example.com:port", "*.*.example.com", "nexus.*.com", "https://", "https://user@nexus.example.com/"} {
+			t.Setenv("BP_GRADLE_ALLOWED_REPOSITORIES", entry)
+
+			_, _, err := gradle.NewRepositoryAllowlist(cr)
+			Expect(err).To(MatchError(fmt.Sprintf("invalid BP_GRADLE_ALLOWED_REPOSITORIES entry %s, must be a host or an absolute URL", entry)))
+		}
+	})
+
+	it("verifies the Gradle Plugin Portal if pluginManagement declares no repositories", func() {
+		Expect(gradle.RepositoryAllowlistScript).To(ContainSubstring(`def gradlePluginPortal = 'https://plugins.gradle.org/m2/'`))
+		Expect(gradle.RepositoryAllowlistScript).To(ContainSubstring(
+			`if (evaluatedSettings.pluginManagement.repositories.isEmpty() && !isAllowed(new URI(gradlePluginPortal))) {`))
+
+		t.Setenv("BP_GRADLE_ALLOWED_REPOSITORIES", "nexus.example.com")
+		allowlist, _, err := gradle.NewRepositoryAllowlist(cr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allowlist.Allows("https://plugins.gradle.org/m2/")).To(BeFalse())
+
+		t.Setenv("BP_GRADLE_ALLOWED_REPOSITORIES", "nexus.example.com,https://plugins.gradle.org/m2/")
+		allowlist, _, err = gradle.NewRepositoryAllowlist(cr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allowlist.Allows("https://plugins.gradle.org/m2/")).To(BeTrue())
+	})
+
+	it("compares the normalized scheme, host, port and path in the init script", func() {
+		Expect(gradle.RepositoryAllowlistScript).To(ContainSubstring(`def (entryScheme, entryHost, entryPort, entryPath) = entry`))
+		Expect(gradle.RepositoryAllowlistScript).To(ContainSubstring(`(entryScheme == '*' || entryScheme == scheme)`))
+		Expect(gradle.RepositoryAllowlistScript).To(ContainSubstring(`(entryPort == '*' || entryPort == port.toString())`))
+		Expect(gradle.RepositoryAllowlistScript).To(ContainSubstring(`path.startsWith(entryPath)`))
+	})
+
+	it("verifies project, buildscript and settings repositories", func() {
+		Expect(gradle.RepositoryAllowlistScript).To(ContainSubstring(`verify('settings pluginManagement', evaluatedSettings.pluginManagement.repositories)`))
+		Expect(gradle.RepositoryAllowlistScript).To(ContainSubstring(`verify('settings dependencyResolutionManagement', evaluatedSettings.dependencyResolutionManagement.repositories)`))
+		Expect(gradle.RepositoryAllowlistScript).To(ContainSubstring(`verify("buildscript of project ${project.path}", project.buildscript.repositories)`))
+		Expect(gradle.RepositoryAllowlistScript).To(ContainSubstring(`verify("project ${project.path}", project.repositories)`))
+	})
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bindings"
+)
+
+// RepositoryMirrorScript rewrites the well-known public repositories declared by projects, buildscripts, settings and
+// pluginManagement to the mirrors passed in the BPI_GRADLE_* environment variables.
+const RepositoryMirrorScript = `// Generated by the Paketo Gradle Buildpack
+import org.gradle.util.GradleVersion
+
+def repositoryMirror = System.getenv('BPI_GRADLE_REPOSITORY_MIRROR')
+def pluginRepositoryMirror = System.getenv('BPI_GRADLE_PLUGIN_REPOSITORY_MIRROR')
+def mirrorUsername = System.getenv('BPI_GRADLE_REPOSITORY_MIRROR_USERNAME')
+def mirrorPassword = System.getenv('BPI_GRADLE_REPOSITORY_MIRROR_PASSWORD')
+
+// mavenCentral(), jcenter() and google()
+def repositoryHosts = ['repo.maven.apache.org', 'repo1.maven.org', 'jcenter.bintray.com', 'dl.google.com', 'maven.google.com']
+// gradlePluginPortal()
+def pluginRepositoryHosts = ['plugins.gradle.org']
+
+def mirror = { ArtifactRepository repository ->
+    if (!(repository instanceof MavenArtifactRepository) || repository.url == null) {
+        return
+    }
+
+    def target = null
+    if (repositoryMirror && repositoryHosts.contains(repository.url.host)) {
+        target = repositoryMirror
+    } else if (pluginRepositoryMirror && pluginRepositoryHosts.contains(repository.url.host)) {
+        target = pluginRepositoryMirror
+    }
+    if (target == null) {
+        return
+    }
+
+    logger.info("Mirroring repository ${repository.name} at ${repository.url} to ${target}")
+    repository.url = target
+    if (mirrorUsername && mirrorPassword) {
+        repository.credentials.username = mirrorUsername
+        repository.credentials.password = mirrorPassword
+    }
+}
+
+if (GradleVersion.current() >= GradleVersion.version('6.0')) {
+    beforeSettings { settings ->
+        settings.pluginManagement.repositories.all(mirror)
+    }
+}
+
+settingsEvaluated { settings ->
+    if (GradleVersion.current() < GradleVersion.version('6.0')) {
+        settings.pluginManagement.repositories.all(mirror)
+    }
+
+    // an empty pluginManagement falls back to the Gradle Plugin Portal which has to be declared to be mirrored
+    if (pluginRepositoryMirror && settings.pluginManagement.repositories.isEmpty()) {
+        settings.pluginManagement.repositories.gradlePluginPortal()
+    }
+
+    if (GradleVersion.current() >= GradleVersion.version('6.8')) {
+        settings.dependencyResolutionManagement.repositories.all(mirror)
+    }
+}
+
+allprojects {
+    buildscript.repositories.all(mirror)
+    repositories.all(mirror)
+}
+`
+
+// RepositoryMirror is the configuration of the repository mirror init script.
+type RepositoryMirror struct {
+	Mirror       string
+	PluginMirror string
+	Username     string
+	Password     string
+}
+
+// NewRepositoryMirror resolves BP_GRADLE_REPOSITORY_MIRROR and BP_GRADLE_PLUGIN_REPOSITORY_MIRROR, the latter
+// defaulting to the former, and the credentials of a gradle-repositories binding. It returns false if no mirror is
+// configured.
+func NewRepositoryMirror(cr libpak.ConfigurationResolver, platformBindings libcnb.Bindings) (RepositoryMirror, bool, error) {
+	var r RepositoryMirror
+
+	r.Mirror, _ = cr.Resolve("BP_GRADLE_REPOSITORY_MIRROR")
+	r.PluginMirror, _ = cr.Resolve("BP_GRADLE_PLUGIN_REPOSITORY_MIRROR")
+	if r.Mirror == "" && r.PluginMirror == "" {
+		return RepositoryMirror{}, false, nil
+	} else if r.PluginMirror == "" {
+		r.PluginMirror = r.Mirror
+	}
+
+	for _, c := range []struct{ name, value string }{
+		{"BP_GRADLE_REPOSITORY_MIRROR", r.Mirror},
+		{"BP_GRADLE_PLUGIN_REPOSITORY_MIRROR", r.PluginMirror},
+	} {
+		if c.value == "" {
+			continue
+		}
+		if u, err := url.Parse(c.value); err != nil || u.Scheme == "" || u.Host == "" {
+			return RepositoryMirror{}, false, fmt.Errorf("invalid %s %s, must be an absolute URL", c.name, c.value)
+		}
+	}
+
+	if binding, ok, err := bindings.ResolveOne(platformBindings, bindings.OfType("gradle-repositories")); err != nil {
+		return RepositoryMirror{}, false, fmt.Errorf("unable to resolve binding\n%w", err)
+	} else if ok {
+		if r.Username, r.Password, _, err = BindingCredentials(binding); err != nil {
+			return RepositoryMirror{}, false, err
+		}
+	}
+
+	return r, true, nil
+}
+
+// Environment returns the environment variables read by RepositoryMirrorScript.
+func (r RepositoryMirror) Environment() map[string]string {
+	environment := map[string]string{}
+
+	for name, value := range map[string]string{
+		"BPI_GRADLE_REPOSITORY_MIRROR":          r.Mirror,
+		"BPI_GRADLE_PLUGIN_REPOSITORY_MIRROR":   r.PluginMirror,
+		"BPI_GRADLE_REPOSITORY_MIRROR_USERNAME": r.Username,
+		"BPI_GRADLE_REPOSITORY_MIRROR_PASSWORD": r.Password,
+	} {
+		if value != "" {
+			environment[name] = value
+		}
+	}
+
+	return environment
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle_test
+
+import (
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/gradle/v7/gradle"
+)
+
+func testRepositoryMirror(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cr libpak.ConfigurationResolver
+	)
+
+	it("is not configured without a mirror", func() {
+		_, ok, err := gradle.NewRepositoryMirror(cr, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+
+	context("BP_GRADLE_REPOSITORY_MIRROR is set", func() {
+		it.Before(func() {
+			t.Setenv("BP_GRADLE_REPOSITORY_MIRROR", "https://nexus.example.com/repository/maven-public/")
+		})
+
+		it("uses the mirror for plugin repositories", func() {
+			mirror, ok, err := gradle.NewRepositoryMirror(cr, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+
+			Expect(mirror).To(Equal(gradle.RepositoryMirror{
+				Mirror:       "https://nexus.example.com/repository/maven-public/",
+				PluginMirror: "https://nexus.example.com/repository/maven-public/",
+			}))
+			Expect(mirror.Environment()).To(Equal(map[string]string{
+				"BPI_GRADLE_REPOSITORY_MIRROR":        "https://nexus.example.com/repository/maven-public/",
+				"BPI_GRADLE_PLUGIN_REPOSITORY_MIRROR": "https://nexus.example.com/repository/maven-public/",
+			}))
+		})
+
+		it("uses BP_GRADLE_PLUGIN_REPOSITORY_MIRROR for plugin repositories", func() {
+			t.Setenv("BP_GRADLE_PLUGIN_REPOSITORY_MIRROR", "https://nexus.example.com/repository/gradle-plugins/")
+
+			mirror, _, err := gradle.NewRepositoryMirror(cr, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(mirror.PluginMirror).To(Equal("https://nexus.example.com/repository/gradle-plugins/"))
+		})
+
+		it("reads credentials from a gradle-repositories binding", func() {
+			mirror, _, err := gradle.NewRepositoryMirror(cr, libcnb.Bindings{
+				{Name: "some-repositories", Type: "gradle-repositories", Secret: map[string]string{"username": "user\n", "token": "secret-token\n"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(mirror.Environment()).To(HaveKeyWithValue("BPI_GRADLE_REPOSITORY_MIRROR_USERNAME", "user"))
+			Expect(mirror.Environment()).To(HaveKeyWithValue("BPI_GRADLE_REPOSITORY_MIRROR_PASSWORD", "secret-token"))
+		})
+
+		it("fails with incomplete credentials", func() {
+			_, _, err := gradle.NewRepositoryMirror(cr, libcnb.Bindings{
+				{Name: "some-repositories", Type: "gradle-repositories", Secret: map[string]string{"username": "user"}},
+			})
+			Expect(err).To(MatchError(ContainSubstring("binding some-repositories requires a password or token secret")))
+		})
+
+		it("fails with a relative URL", func() {
+			t.Setenv("BP_GRADLE_REPOSITORY_MIRROR", "nexus.example.com")
+
+			_, _, err := gradle.NewRepositoryMirror(cr, nil)
+			Expect(err).To(MatchError("invalid BP_GRADLE_REPOSITORY_MIRROR nexus.example.com, must be an absolute URL"))
+		})
+	})
+
+	context("only BP_GRADLE_PLUGIN_REPOSITORY_MIRROR is set", func() {
+		it.Before(func() {
+			t.Setenv("BP_GRADLE_PLUGIN_REPOSITORY_MIRROR", "https://nexus.example.com/repository/gradle-plugins/")
+		})
+
+		it("only mirrors plugin repositories", func() {
+			mirror, ok, err := gradle.NewRepositoryMirror(cr, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+
+			Expect(mirror.Environment()).To(Equal(map[string]string{
+				"BPI_GRADLE_PLUGIN_REPOSITORY_MIRROR": "https://nexus.example.com/repository/gradle-plugins/",
+			}))
+		})
+	})
+
+	it("mirrors project, buildscript, settings and pluginManagement repositories", func() {
+		Expect(gradle.RepositoryMirrorScript).To(ContainSubstring("settings.pluginManagement.repositories.all(mirror)"))
+		Expect(gradle.RepositoryMirrorScript).To(ContainSubstring("settings.dependencyResolutionManagement.repositories.all(mirror)"))
+		Expect(gradle.RepositoryMirrorScript).To(ContainSubstring("allprojects {\n    buildscript.repositories.all(mirror)\n    repositories.all(mirror)\n}"))
+	})
+}
@@ -0,0 +1,225 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+// TestReports contributes a build layer that the Executor exports the test results of a build to once Gradle has run.
+// It contains the JUnit XML results in build/test-results and the HTML reports in build/reports/tests of each project,
+// at the same path relative to the application. As the lifecycle only persists layers of a successful build, the layer
+// keeps the reports of the previous build when tests fail, so the summary that is logged is all that remains of them.
+type TestReports struct {
+	ApplicationPath string
+	Logger          bard.Logger
+}
+
+// testSummary is the number of tests of a build by outcome and the names of the failed tests.
+type testSummary struct {
+	Failed   int
+	Failures []string
+	Passed   int
+	Skipped  int
+}
+
+type junitTestSuite struct {
+	TestCases  []junitTestCase  `xml:"testcase"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestCase struct {
+	ClassName string     `xml:"classname,attr"`
+	Errors    []struct{} `xml:"error"`
+	Failures  []struct{} `xml:"failure"`
+	Name      string     `xml:"name,attr"`
+	Skipped   *struct{}  `xml:"skipped"`
+}
+
+func (t TestReports) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	if err := os.MkdirAll(layer.Path, 0755); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to create directory %s\n%w", layer.Path, err)
+	}
+
+	// reports are only replaced when Gradle runs, so that those of a reused application layer are kept
+	layer.LayerTypes = libcnb.LayerTypes{Build: true, Cache: true}
+	return layer, nil
+}
+
+func (TestReports) Name() string {
+	return "test-reports"
+}
+
+// Path returns the path of the layer in the layers directory.
+func (t TestReports) Path(layersPath string) string {
+	return filepath.Join(layersPath, t.Name())
+}
+
+// Export logs a summary of the test results of all projects of the application and replaces the contents of the layer
+// with their results and reports.
+func (t TestReports) Export(layersPath string) error {
+	projects, err := t.projects()
+	if err != nil {
+		return err
+	}
+
+	summary, err := t.summarize(projects)
+	if err != nil {
+		return err
+	}
+
+	if len(projects) == 0 {
+		t.Logger.Header("No test results found")
+	} else {
+		t.Logger.Headerf("Tests: %d passed, %d failed, %d skipped", summary.Passed, summary.Failed, summary.Skipped)
+		for _, failure := range summary.Failures {
+			t.Logger.Bodyf("FAILED %s", failure)
+		}
+	}
+
+	path := t.Path(layersPath)
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fmt.Errorf("unable to read %s\n%w", path, err)
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(path, entry.Name())); err != nil {
+			return fmt.Errorf("unable to remove %s\n%w", filepath.Join(path, entry.Name()), err)
+		}
+	}
+
+	for _, project := range projects {
+		rel, err := filepath.Rel(t.ApplicationPath, project)
+		if err != nil {
+			return fmt.Errorf("unable to determine relative path of %s\n%w", project, err)
+		}
+
+		for _, dir := range []string{filepath.Join("build", "test-results"), filepath.Join("build", "reports", "tests")} {
+			source := filepath.Join(project, dir)
+			if ok, err := sherpa.DirExists(source); err != nil {
+				return fmt.Errorf("unable to check for %s\n%w", source, err)
+			} else if !ok {
+				continue
+			}
+
+			if err := sherpa.CopyDir(source, filepath.Join(path, rel, dir)); err != nil {
+				return fmt.Errorf("unable to copy %s\n%w", source, err)
+			}
+		}
+	}
+
+	if len(projects) > 0 {
+		t.Logger.Bodyf("Exported test reports to %s", path)
+	}
+
+	return nil
+}
+
+// projects returns the directories of the projects below ApplicationPath that have a build/test-results directory.
+func (t TestReports) projects() ([]string, error) {
+	var projects []string
+
+	err := filepath.WalkDir(t.ApplicationPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		switch d.Name() {
+		case ".git", ".gradle", "node_modules":
+			return filepath.SkipDir
+		case "test-results":
+			if filepath.Base(filepath.Dir(path)) == "build" {
+				projects = append(projects, filepath.Dir(filepath.Dir(path)))
+				return filepath.SkipDir
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to find test results in %s\n%w", t.ApplicationPath, err)
+	}
+
+	sort.Strings(projects)
+	return projects, nil
+}
+
+func (t TestReports) summarize(projects []string) (testSummary, error) {
+	var summary testSummary
+
+	for _, project := range projects {
+		results := filepath.Join(project, "build", "test-results")
+
+		err := filepath.WalkDir(results, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !strings.HasPrefix(d.Name(), "TEST-") || filepath.Ext(d.Name()) != ".xml" {
+				return nil
+			}
+
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("unable to read %s\n%w", path, err)
+			}
+
+			var suite junitTestSuite
+			if err := xml.Unmarshal(b, &suite); err != nil {
+				return fmt.Errorf("unable to parse %s\n%w", path, err)
+			}
+
+			summary.add(suite)
+			return nil
+		})
+		if err != nil {
+			return testSummary{}, fmt.Errorf("unable to read test results in %s\n%w", results, err)
+		}
+	}
+
+	return summary, nil
+}
+
+func (s *testSummary) add(suite junitTestSuite) {
+	for _, c := range suite.TestCases {
+		switch {
+		case len(c.Failures) > 0 || len(c.Errors) > 0:
+			s.Failed++
+			s.Failures = append(s.Failures, fmt.Sprintf("%s > %s", c.ClassName, c.Name))
+		case c.Skipped != nil:
+			s.Skipped++
+		default:
+			s.Passed++
+		}
+	}
+
+	for _, nested := range suite.TestSuites {
+		s.add(nested)
+	}
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/gradle/v7/gradle"
+)
+
+func testTestReports(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		ctx     libcnb.BuildContext
+		layer   libcnb.Layer
+		output  *bytes.Buffer
+		reports gradle.TestReports
+	)
+
+	it.Before(func() {
+		var err error
+
+		ctx.Application.Path = t.TempDir()
+		ctx.Layers.Path = t.TempDir()
+		output = &bytes.Buffer{}
+
+		reports = gradle.TestReports{ApplicationPath: ctx.Application.Path, Logger: bard.NewLogger(output)}
+
+		layer, err = ctx.Layers.Layer(reports.Name())
+		Expect(err).NotTo(HaveOccurred())
+		layer, err = reports.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it("contributes a cached build layer", func() {
+		Expect(layer.LayerTypes).To(Equal(libcnb.LayerTypes{Build: true, Cache: true}))
+		Expect(reports.Path(ctx.Layers.Path)).To(Equal(layer.Path))
+	})
+
+	context("test results exist", func() {
+		it.Before(func() {
+			writeFile(t, ctx.Application.Path, "build/test-results/test/TEST-com.example.AppTest.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="com.example.AppTest" tests="3" skipped="1" failures="1" errors="0">
+  <testcase name="passes()" classname="com.example.AppTest" time="0.01"/>
+  <testcase name="fails()" classname="com.example.AppTest" time="0.01">
+    <failure message="expected: true" type="org.opentest4j.AssertionFailedError">stack trace</failure>
+  </testcase>
+  <testcase name="skipped()" classname="com.example.AppTest" time="0.0">
+    <skipped/>
+  </testcase>
+</testsuite>
+`)
+			writeFile(t, ctx.Application.Path, "build/reports/tests/test/index.html", "<html></html>")
+			writeFile(t, ctx.Application.Path, "lib/build/test-results/test/TEST-com.example.LibTest.xml", `<testsuite name="com.example.LibTest">
+  <testcase name="passes()" classname="com.example.LibTest"/>
+  <testcase name="errors()" classname="com.example.LibTest"><error message="boom"/></testcase>
+</testsuite>
+`)
+			writeFile(t, ctx.Application.Path, "lib/build/test-results/test/binary/results.bin", "")
+		})
+
+		it("logs a summary with the failed tests", func() {
+			Expect(reports.Export(ctx.Layers.Path)).To(Succeed())
+
+			Expect(output.String()).To(ContainSubstring("Tests: 2 passed, 2 failed, 1 skipped"))
+			Expect(output.String()).To(ContainSubstring("FAILED com.example.AppTest > fails()"))
+			Expect(output.String()).To(ContainSubstring("FAILED com.example.LibTest > errors()"))
+		})
+
+		it("exports results and reports of all projects to the layer", func() {
+			Expect(os.WriteFile(filepath.Join(layer.Path, "stale.xml"), []byte{}, 0644)).To(Succeed())
+
+			Expect(reports.Export(ctx.Layers.Path)).To(Succeed())
+
+			Expect(filepath.Join(layer.Path, "build", "test-results", "test", "TEST-com.example.AppTest.xml")).To(BeARegularFile())
+			Expect(filepath.Join(layer.Path, "build", "reports", "tests", "test", "index.html")).To(BeARegularFile())
+			Expect(filepath.Join(layer.Path, "lib", "build", "test-results", "test", "TEST-com.example.LibTest.xml")).To(BeARegularFile())
+			Expect(filepath.Join(layer.Path, "stale.xml")).NotTo(BeAnExistingFile())
+		})
+
+		it("fails on invalid results", func() {
+			writeFile(t, ctx.Application.Path, "build/test-results/test/TEST-com.example.BrokenTest.xml", "<testsuite>")
+
+			Expect(reports.Export(ctx.Layers.Path)).To(MatchError(ContainSubstring("unable to parse")))
+		})
+	})
+
+	it("logs that no test results exist", func() {
+		Expect(reports.Export(ctx.Layers.Path)).To(Succeed())
+
+		Expect(output.String()).To(ContainSubstring("No test results found"))
+	})
+}
@@ -0,0 +1,296 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+var toolchainPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`JavaLanguageVersion\s*\.\s*of\s*\(\s*"?(\d+)"?\s*\)`),
+	regexp.MustCompile(`jvmToolchain\s*\(\s*(\d+)\s*\)`),
+}
+
+// JDK is a JDK installed by a buildpack.
+type JDK struct {
+	Home    string
+	Version int
+}
+
+// InstalledJDKs returns the JDK at javaHome followed by the JDKs in the layers of other buildpacks below layersRoot,
+// i.e. layer directories with bin/javac and a release file. Directories without a release file are ignored.
+func InstalledJDKs(javaHome string, layersRoot string) ([]JDK, error) {
+	var candidates []string
+	if javaHome != "" {
+		candidates = append(candidates, javaHome)
+	}
+
+	if layersRoot != "" {
+		javacs, err := filepath.Glob(filepath.Join(layersRoot, "*", "*", "bin", "javac"))
+		if err != nil {
+			return nil, fmt.Errorf("unable to find JDKs in %s\n%w", layersRoot, err)
+		}
+		sort.Strings(javacs)
+		for _, javac := range javacs {
+			candidates = append(candidates, filepath.Dir(filepath.Dir(javac)))
+		}
+	}
+
+	var jdks []JDK
+	seen := map[string]bool{}
+	for _, home := range candidates {
+		resolved, err := filepath.EvalSymlinks(home)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to resolve %s\n%w", home, err)
+		}
+		if seen[resolved] {
+			continue
+		}
+		seen[resolved] = true
+
+		version, ok, err := jdkVersion(home)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+
+		jdks = append(jdks, JDK{Home: home, Version: version})
+	}
+
+	return jdks, nil
+}
+
+// jdkVersion returns the feature version of the JAVA_VERSION in the release file of the JDK at home, e.g. 8 for 1.8.0
+// and 17 for 17.0.9.
+func jdkVersion(home string) (int, bool, error) {
+	file := filepath.Join(home, "release")
+	in, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, fmt.Errorf("unable to open %s\n%w", file, err)
+	}
+	defer in.Close()
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "JAVA_VERSION=")
+		if !ok {
+			continue
+		}
+
+		value = strings.TrimPrefix(strings.Trim(value, `"`), "1.")
+		feature, _, _ := strings.Cut(value, ".")
+		version, err := strconv.Atoi(feature)
+		if err != nil {
+			return 0, false, fmt.Errorf("unable to parse JAVA_VERSION of %s\n%w", file, err)
+		}
+		return version, true, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, false, fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	return 0, false, nil
+}
+
+// RequestedToolchains returns the Java language versions requested by JavaLanguageVersion.of(...) and
+// jvmToolchain(...) outside of comments in the Groovy and Kotlin build and settings scripts and precompiled script
+// plugins below projectDir, mapped to the scripts requesting them relative to projectDir.
+func RequestedToolchains(projectDir string) (map[int][]string, error) {
+	requested := map[int][]string{}
+
+	err := filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", ".gradle", "build", "node_modules":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(projectDir, path)
+		if err != nil {
+			return fmt.Errorf("unable to determine relative path of %s\n%w", path, err)
+		}
+
+		if !isBuildScript(rel) {
+			return nil
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("unable to read %s\n%w", path, err)
+		}
+
+		for _, pattern := range toolchainPatterns {
+			for _, match := range pattern.FindAllStringSubmatch(stripComments(string(b)), -1) {
+				version, err := strconv.Atoi(match[1])
+				if err != nil {
+					return fmt.Errorf("unable to parse toolchain version %s in %s\n%w", match[1], path, err)
+				}
+				if !slices.Contains(requested[version], rel) {
+					requested[version] = append(requested[version], rel)
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to find toolchains in %s\n%w", projectDir, err)
+	}
+
+	return requested, nil
+}
+
+// isBuildScript returns whether the script at path, relative to the project, is a build or settings script or a
+// precompiled script plugin, rather than e.g. a script that is only applied conditionally or not at all.
+func isBuildScript(path string) bool {
+	switch filepath.Base(path) {
+	case "build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts":
+		return true
+	}
+
+	if !strings.HasSuffix(path, ".gradle") && !strings.HasSuffix(path, ".gradle.kts") {
+		return false
+	}
+
+	slashed := "/" + filepath.ToSlash(path)
+	return strings.Contains(slashed, "/src/main/groovy/") || strings.Contains(slashed, "/src/main/kotlin/")
+}
+
+// stripComments removes the // and /* */ comments of a Groovy or Kotlin script, leaving string literals untouched.
+func stripComments(script string) string {
+	var out strings.Builder
+
+	for i := 0; i < len(script); {
+		switch {
+		case strings.HasPrefix(script[i:], "//"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				return out.String()
+			}
+			i += end
+		case strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				return out.String()
+			}
+			i += end + 4
+			out.WriteByte(' ')
+		case strings.HasPrefix(script[i:], `"""`), strings.HasPrefix(script[i:], "'''"):
+			quote := script[i : i+3]
+			end := strings.Index(script[i+3:], quote)
+			if end < 0 {
+				out.WriteString(script[i:])
+				return out.String()
+			}
+			out.WriteString(script[i : i+end+6])
+			i += end + 6
+		case script[i] == '"' || script[i] == '\'':
+			j := i + 1
+			for j < len(script) && script[j] != script[i] && script[j] != '\n' {
+				if script[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j < len(script) {
+				j++
+			}
+			out.WriteString(script[i:min(j, len(script))])
+			i = j
+		default:
+			out.WriteByte(script[i])
+			i++
+		}
+	}
+
+	return out.String()
+}
+
+// VerifyToolchains returns an error naming the requested toolchain versions and the scripts requesting them if no JDK
+// of jdks has the version.
+func VerifyToolchains(requested map[int][]string, jdks []JDK) error {
+	installed := map[int]bool{}
+	var descriptions []string
+	for _, jdk := range jdks {
+		installed[jdk.Version] = true
+		descriptions = append(descriptions, fmt.Sprintf("Java %d at %s", jdk.Version, jdk.Home))
+	}
+
+	var versions []int
+	for version := range requested {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+
+	for _, version := range versions {
+		if !installed[version] {
+			return fmt.Errorf("no installed JDK matches the Java %d toolchain requested by %s, installed are %s\n"+
+				"Set $BP_JVM_VERSION to %d to install a matching JDK or enable org.gradle.java.installations.auto-download",
+				version, strings.Join(requested[version], ", "), strings.Join(descriptions, ", "), version)
+		}
+	}
+
+	return nil
+}
+
+// ToolchainAutoDownload returns whether org.gradle.java.installations.auto-download is enabled by the properties
+// files at paths, later files taking precedence, or by overrides. Missing files are ignored.
+func ToolchainAutoDownload(overrides map[string]string, paths ...string) (bool, error) {
+	value := ""
+	for _, path := range paths {
+		if ok, err := sherpa.FileExists(path); err != nil {
+			return false, fmt.Errorf("unable to check for %s\n%w", path, err)
+		} else if !ok {
+			continue
+		}
+
+		p, err := loadProperties(path)
+		if err != nil {
+			return false, fmt.Errorf("unable to read %s\n%w", path, err)
+		}
+		if v, ok := p["org.gradle.java.installations.auto-download"]; ok {
+			value = v
+		}
+	}
+	if v, ok := overrides["org.gradle.java.installations.auto-download"]; ok {
+		value = v
+	}
+
+	return strings.EqualFold(strings.TrimSpace(value), "true"), nil
+}
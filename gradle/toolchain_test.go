@@ -0,0 +1,134 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/gradle/v7/gradle"
+)
+
+func testToolchain(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layersRoot string
+		projectDir string
+	)
+
+	jdk := func(home string, version string) {
+		Expect(os.MkdirAll(filepath.Join(home, "bin"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(home, "bin", "javac"), []byte{}, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(home, "release"), []byte("IMPLEMENTOR=\"BellSoft\"\nJAVA_VERSION=\""+version+"\"\n"), 0644)).To(Succeed())
+	}
+
+	it.Before(func() {
+		layersRoot = t.TempDir()
+		projectDir = t.TempDir()
+	})
+
+	context("InstalledJDKs", func() {
+		it("returns JAVA_HOME followed by JDK layers of other buildpacks", func() {
+			javaHome := filepath.Join(layersRoot, "paketo-buildpacks_bellsoft-liberica", "jdk")
+			jdk(javaHome, "17.0.9")
+			jdk(filepath.Join(layersRoot, "paketo-buildpacks_adoptium", "jdk"), "1.8.0_392")
+			Expect(os.MkdirAll(filepath.Join(layersRoot, "paketo-buildpacks_gradle", "cache"), 0755)).To(Succeed())
+
+			Expect(gradle.InstalledJDKs(javaHome, layersRoot)).To(Equal([]gradle.JDK{
+				{Home: javaHome, Version: 17},
+				{Home: filepath.Join(layersRoot, "paketo-buildpacks_adoptium", "jdk"), Version: 8},
+			}))
+		})
+
+		it("ignores a missing JAVA_HOME", func() {
+			Expect(gradle.InstalledJDKs(filepath.Join(layersRoot, "missing"), layersRoot)).To(BeEmpty())
+		})
+	})
+
+	context("RequestedToolchains", func() {
+		it("finds toolchains in Groovy and Kotlin build scripts", func() {
+			Expect(os.WriteFile(filepath.Join(projectDir, "build.gradle"),
+				[]byte("java {\n  toolchain {\n    languageVersion = JavaLanguageVersion.of(17)\n  }\n}\n"), 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(projectDir, "lib"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(projectDir, "lib", "build.gradle.kts"),
+				[]byte("kotlin {\n  jvmToolchain(21)\n}\njava.toolchain.languageVersion.set(JavaLanguageVersion.of(\"17\"))\n"), 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(projectDir, "build"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(projectDir, "build", "generated.gradle"), []byte("jvmToolchain(11)"), 0644)).To(Succeed())
+
+			Expect(gradle.RequestedToolchains(projectDir)).To(Equal(map[int][]string{
+				17: {"build.gradle", filepath.Join("lib", "build.gradle.kts")},
+				21: {filepath.Join("lib", "build.gradle.kts")},
+			}))
+		})
+
+		it("ignores comments, strings and scripts other than build scripts", func() {
+			Expect(os.WriteFile(filepath.Join(projectDir, "build.gradle"), []byte(`java {
+  // JavaLanguageVersion.of(8)
+  toolchain { languageVersion = JavaLanguageVersion.of(17) } /* jvmToolchain(8)
+  jvmToolchain(8) */
+  description = "see https://example.com"; kotlin.jvmToolchain(11)
+}
+`), 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(projectDir, "gradle"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(projectDir, "gradle", "unused.gradle"), []byte("jvmToolchain(8)"), 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(projectDir, "buildSrc", "src", "main", "kotlin"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(projectDir, "buildSrc", "src", "main", "kotlin", "java-conventions.gradle.kts"),
+				[]byte("kotlin { jvmToolchain(21) }"), 0644)).To(Succeed())
+
+			Expect(gradle.RequestedToolchains(projectDir)).To(Equal(map[int][]string{
+				11: {"build.gradle"},
+				17: {"build.gradle"},
+				21: {filepath.Join("buildSrc", "src", "main", "kotlin", "java-conventions.gradle.kts")},
+			}))
+		})
+	})
+
+	context("VerifyToolchains", func() {
+		it("passes if a JDK matches each toolchain", func() {
+			Expect(gradle.VerifyToolchains(map[int][]string{17: {"build.gradle"}}, []gradle.JDK{{Home: "/jdk", Version: 17}})).To(Succeed())
+		})
+
+		it("fails if no JDK matches a toolchain", func() {
+			err := gradle.VerifyToolchains(map[int][]string{21: {"build.gradle"}}, []gradle.JDK{{Home: "/jdk", Version: 17}})
+
+			Expect(err).To(MatchError(ContainSubstring("no installed JDK matches the Java 21 toolchain requested by build.gradle, installed are Java 17 at /jdk")))
+			Expect(err).To(MatchError(ContainSubstring("Set $BP_JVM_VERSION to 21")))
+		})
+	})
+
+	context("ToolchainAutoDownload", func() {
+		it("is disabled by default", func() {
+			Expect(gradle.ToolchainAutoDownload(nil, filepath.Join(projectDir, "gradle.properties"))).To(BeFalse())
+		})
+
+		it("is enabled by properties files and overrides in order", func() {
+			first := filepath.Join(projectDir, "first.properties")
+			second := filepath.Join(projectDir, "second.properties")
+			Expect(os.WriteFile(first, []byte("org.gradle.java.installations.auto-download=true\n"), 0644)).To(Succeed())
+			Expect(os.WriteFile(second, []byte("org.gradle.java.installations.auto-download=false\n"), 0644)).To(Succeed())
+
+			Expect(gradle.ToolchainAutoDownload(nil, first)).To(BeTrue())
+			Expect(gradle.ToolchainAutoDownload(nil, first, second)).To(BeFalse())
+			Expect(gradle.ToolchainAutoDownload(map[string]string{"org.gradle.java.installations.auto-download": "true"}, second)).To(BeTrue())
+		})
+	})
+}
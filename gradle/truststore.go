@@ -0,0 +1,203 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+	"github.com/pavlo-v-chernykh/keystore-go/v4"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// TruststorePassword is the password of the truststore. It only protects the integrity of public certificates.
+const TruststorePassword = pkcs12.DefaultPassword
+
+// Truststore assembles a PKCS12 truststore from the default certificates of the JDK and the certificates of
+// ca-certificates bindings in a build-only layer.
+type Truststore struct {
+	Bindings libcnb.Bindings
+	JavaHome string
+	Logger   bard.Logger
+}
+
+func (t Truststore) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	var entries []pkcs12.TrustStoreEntry
+
+	if path, ok, err := t.jdkTruststore(); err != nil {
+		return libcnb.Layer{}, err
+	} else if !ok {
+		t.Logger.Bodyf("WARNING: no JDK truststore found in %s, only bound certificates are trusted", t.JavaHome)
+	} else {
+		certs, err := readTruststore(path)
+		if err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to read %s\n%w", path, err)
+		}
+		for _, c := range certs {
+			entries = append(entries, pkcs12.TrustStoreEntry{Cert: c, FriendlyName: c.Subject.String()})
+		}
+	}
+
+	added := 0
+	for _, binding := range t.Bindings {
+		var names []string
+		for name := range binding.Secret {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			path, _ := binding.SecretFilePath(name)
+			certs, err := readCertificates(path)
+			if err != nil {
+				return libcnb.Layer{}, fmt.Errorf("unable to read certificates from %s\n%w", path, err)
+			}
+			for i, c := range certs {
+				entries = append(entries, pkcs12.TrustStoreEntry{Cert: c, FriendlyName: fmt.Sprintf("%s-%s-%d", binding.Name, name, i)})
+				added++
+			}
+		}
+	}
+	t.Logger.Bodyf("Adding %d bound CA certificates to Gradle truststore", added)
+
+	data, err := pkcs12.LegacyDES.EncodeTrustStoreEntries(entries, TruststorePassword)
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to encode truststore\n%w", err)
+	}
+
+	if err := os.MkdirAll(layer.Path, 0755); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to create directory %s\n%w", layer.Path, err)
+	}
+
+	file := filepath.Join(layer.Path, "truststore.p12")
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to write %s\n%w", file, err)
+	}
+
+	return layer, nil
+}
+
+func (Truststore) Name() string {
+	return "truststore"
+}
+
+// Path returns the path of the truststore in the layers directory.
+func (t Truststore) Path(layersPath string) string {
+	return filepath.Join(layersPath, t.Name(), "truststore.p12")
+}
+
+// SystemProperties returns the system properties that make a JVM use the truststore.
+func (t Truststore) SystemProperties(layersPath string) []string {
+	return []string{
+		fmt.Sprintf("-Djavax.net.ssl.trustStore=%s", t.Path(layersPath)),
+		"-Djavax.net.ssl.trustStoreType=PKCS12",
+		fmt.Sprintf("-Djavax.net.ssl.trustStorePassword=%s", TruststorePassword),
+	}
+}
+
+// jdkTruststore returns the path of the cacerts file of a JDK 9+ or a JDK 8.
+func (t Truststore) jdkTruststore() (string, bool, error) {
+	if t.JavaHome == "" {
+		return "", false, nil
+	}
+
+	for _, path := range []string{
+		filepath.Join(t.JavaHome, "lib", "security", "cacerts"),
+		filepath.Join(t.JavaHome, "jre", "lib", "security", "cacerts"),
+	} {
+		if ok, err := sherpa.FileExists(path); err != nil {
+			return "", false, fmt.Errorf("unable to check for %s\n%w", path, err)
+		} else if ok {
+			return path, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+// readTruststore reads the certificates of a JKS or PKCS12 truststore protected by the default password or none.
+func readTruststore(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(data, []byte{0xFE, 0xED, 0xFE, 0xED}) {
+		ks := keystore.New(keystore.WithOrderedAliases())
+		if err := ks.Load(bytes.NewReader(data), []byte(pkcs12.DefaultPassword)); err != nil {
+			return nil, fmt.Errorf("unable to decode keystore\n%w", err)
+		}
+
+		var certs []*x509.Certificate
+		for _, alias := range ks.Aliases() {
+			entry, err := ks.GetTrustedCertificateEntry(alias)
+			if err != nil {
+				continue
+			}
+			c, err := x509.ParseCertificate(entry.Certificate.Content)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse certificate %s\n%w", alias, err)
+			}
+			certs = append(certs, c)
+		}
+		return certs, nil
+	}
+
+	certs, err := pkcs12.DecodeTrustStore(data, pkcs12.DefaultPassword)
+	if err != nil {
+		if certs, err = pkcs12.DecodeTrustStore(data, ""); err != nil {
+			return nil, fmt.Errorf("unable to decode truststore\n%w", err)
+		}
+	}
+	return certs, nil
+}
+
+// readCertificates reads the PEM encoded certificates of a file. Files without certificates are ignored.
+func readCertificates(path string) ([]*x509.Certificate, error) {
+	rest, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse certificate\n%w", err)
+		}
+		certs = append(certs, c)
+	}
+
+	return certs, nil
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/pavlo-v-chernykh/keystore-go/v4"
+	"github.com/sclevine/spec"
+	"software.sslmate.com/src/go-pkcs12"
+
+	"github.com/paketo-buildpacks/gradle/v7/gradle"
+)
+
+func testTruststore(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		ctx        libcnb.BuildContext
+		javaHome   string
+		truststore gradle.Truststore
+	)
+
+	certificate := func(name string) *x509.Certificate {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: name},
+			NotBefore:             time.Now(),
+			NotAfter:              time.Now().Add(time.Hour),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		Expect(err).NotTo(HaveOccurred())
+
+		c, err := x509.ParseCertificate(der)
+		Expect(err).NotTo(HaveOccurred())
+		return c
+	}
+
+	subjects := func(path string) []string {
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		certs, err := pkcs12.DecodeTrustStore(data, gradle.TruststorePassword)
+		Expect(err).NotTo(HaveOccurred())
+
+		var s []string
+		for _, c := range certs {
+			s = append(s, c.Subject.CommonName)
+		}
+		return s
+	}
+
+	it.Before(func() {
+		ctx.Layers.Path = t.TempDir()
+		ctx.Platform.Path = t.TempDir()
+		javaHome = t.TempDir()
+
+		bindingPath := filepath.Join(ctx.Platform.Path, "bindings", "some-certificates")
+		Expect(os.MkdirAll(bindingPath, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(bindingPath, "internal-ca.pem"),
+			pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate("internal-ca").Raw}), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(bindingPath, "README"), []byte("not a certificate"), 0644)).To(Succeed())
+
+		truststore = gradle.Truststore{
+			Bindings: libcnb.Bindings{
+				{
+					Name:   "some-certificates",
+					Type:   "ca-certificates",
+					Path:   bindingPath,
+					Secret: map[string]string{"internal-ca.pem": "", "README": ""},
+				},
+			},
+			JavaHome: javaHome,
+		}
+	})
+
+	contribute := func() string {
+		layer, err := ctx.Layers.Layer(truststore.Name())
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = truststore.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(layer.LayerTypes).To(Equal(libcnb.LayerTypes{}))
+
+		return truststore.Path(ctx.Layers.Path)
+	}
+
+	it("adds bound certificates to a PKCS12 JDK truststore", func() {
+		data, err := pkcs12.Passwordless.EncodeTrustStore([]*x509.Certificate{certificate("public-ca")}, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(javaHome, "lib", "security"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(javaHome, "lib", "security", "cacerts"), data, 0644)).To(Succeed())
+
+		Expect(subjects(contribute())).To(Equal([]string{"public-ca", "internal-ca"}))
+	})
+
+	it("adds bound certificates to a JKS JDK truststore", func() {
+		ks := keystore.New()
+		Expect(ks.SetTrustedCertificateEntry("public-ca", keystore.TrustedCertificateEntry{
+			CreationTime: time.Now(),
+			Certificate:  keystore.Certificate{Type: "X.509", Content: certificate("public-ca").Raw},
+		})).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(javaHome, "jre", "lib", "security"), 0755)).To(Succeed())
+		out, err := os.Create(filepath.Join(javaHome, "jre", "lib", "security", "cacerts"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ks.Store(out, []byte("changeit"))).To(Succeed())
+		Expect(out.Close()).To(Succeed())
+
+		Expect(subjects(contribute())).To(Equal([]string{"public-ca", "internal-ca"}))
+	})
+
+	it("only contains bound certificates without a JDK truststore", func() {
+		Expect(subjects(contribute())).To(Equal([]string{"internal-ca"}))
+	})
+
+	it("returns the system properties of the truststore", func() {
+		Expect(truststore.SystemProperties("/layers")).To(Equal([]string{
+			"-Djavax.net.ssl.trustStore=/layers/truststore/truststore.p12",
+			"-Djavax.net.ssl.trustStoreType=PKCS12",
+			"-Djavax.net.ssl.trustStorePassword=changeit",
+		}))
+	})
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle
+
+import (
+	"crypto/md5"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/magiconair/properties"
+)
+
+var distributionNamePattern = regexp.MustCompile(`^gradle-(.+)-(bin|all)\.zip$`)
+
+const (
+	WrapperBaseGradleUserHome = "GRADLE_USER_HOME"
+	WrapperBaseProject        = "PROJECT"
+)
+
+// WrapperProperties is the content of a gradle/wrapper/gradle-wrapper.properties file.
+type WrapperProperties struct {
+	DistributionBase      string
+	DistributionPath      string
+	DistributionSHA256Sum string
+	DistributionURL       string
+	ZipStoreBase          string
+	ZipStorePath          string
+}
+
+// NewWrapperProperties reads the wrapper properties at paths, later files overriding earlier ones. Missing files are
+// ignored and false is returned if none of them exist.
+func NewWrapperProperties(paths ...string) (WrapperProperties, bool, error) {
+	var existing []string
+	for _, path := range paths {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return WrapperProperties{}, false, fmt.Errorf("unable to stat %s\n%w", path, err)
+		}
+		existing = append(existing, path)
+	}
+	if len(existing) == 0 {
+		return WrapperProperties{}, false, nil
+	}
+
+	p, err := properties.LoadFiles(existing, properties.UTF8, false)
+	if err != nil {
+		return WrapperProperties{}, false, fmt.Errorf("unable to read %s\n%w", strings.Join(existing, ", "), err)
+	}
+
+	return WrapperProperties{
+		DistributionBase:      p.GetString("distributionBase", WrapperBaseGradleUserHome),
+		DistributionPath:      p.GetString("distributionPath", "wrapper/dists"),
+		DistributionSHA256Sum: p.GetString("distributionSha256Sum", ""),
+		DistributionURL:       p.GetString("distributionUrl", ""),
+		ZipStoreBase:          p.GetString("zipStoreBase", WrapperBaseGradleUserHome),
+		ZipStorePath:          p.GetString("zipStorePath", "wrapper/dists"),
+	}, true, nil
+}
+
+// Version returns the Gradle version requested by the distributionUrl, e.g. 8.5 for .../gradle-8.5-bin.zip.
+func (w WrapperProperties) Version() (string, error) {
+	matches, err := w.distributionName()
+	if err != nil {
+		return "", err
+	}
+
+	return matches[1], nil
+}
+
+// Type returns the type of distribution requested by the distributionUrl, either bin or all.
+func (w WrapperProperties) Type() (string, error) {
+	matches, err := w.distributionName()
+	if err != nil {
+		return "", err
+	}
+
+	return matches[2], nil
+}
+
+// DistributionDirectory returns the directory the wrapper expands the distribution to, e.g.
+// <gradleUserHome>/wrapper/dists/gradle-8.5-bin/<hash>.
+func (w WrapperProperties) DistributionDirectory(gradleUserHome string, projectPath string) (string, error) {
+	return w.storeDirectory(w.DistributionBase, w.DistributionPath, gradleUserHome, projectPath)
+}
+
+// MarkerFile returns the file the wrapper uses to mark a distribution as successfully installed.
+func (w WrapperProperties) MarkerFile(gradleUserHome string, projectPath string) (string, error) {
+	dir, err := w.storeDirectory(w.ZipStoreBase, w.ZipStorePath, gradleUserHome, projectPath)
+	if err != nil {
+		return "", err
+	}
+
+	matches, err := w.distributionName()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, fmt.Sprintf("%s.ok", matches[0])), nil
+}
+
+// storeDirectory mirrors the wrapper's PathAssembler: <base>/<path>/<distribution name>/<hash of the URL>, where the
+// hash is the base 36 representation of the MD5 of the URL without credentials.
+func (w WrapperProperties) storeDirectory(base string, storePath string, gradleUserHome string, projectPath string) (string, error) {
+	var root string
+	switch base {
+	case WrapperBaseGradleUserHome:
+		root = gradleUserHome
+	case WrapperBaseProject:
+		root = projectPath
+	default:
+		return "", fmt.Errorf("unknown wrapper base %s", base)
+	}
+
+	matches, err := w.distributionName()
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(w.DistributionURL)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse distributionUrl %s\n%w", w.DistributionURL, err)
+	}
+	u.User = nil
+
+	sum := md5.Sum([]byte(u.String()))
+	hash := new(big.Int).SetBytes(sum[:]).Text(36)
+
+	return filepath.Join(root, storePath, fmt.Sprintf("gradle-%s-%s", matches[1], matches[2]), hash), nil
+}
+
+func (w WrapperProperties) distributionName() ([]string, error) {
+	if w.DistributionURL == "" {
+		return nil, fmt.Errorf("no distributionUrl set")
+	}
+
+	u, err := url.Parse(w.DistributionURL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse distributionUrl %s\n%w", w.DistributionURL, err)
+	}
+
+	matches := distributionNamePattern.FindStringSubmatch(path.Base(u.Path))
+	if matches == nil {
+		return nil, fmt.Errorf("unable to determine Gradle version from distributionUrl %s", w.DistributionURL)
+	}
+
+	return matches, nil
+}
+
+// WrapperCredentials returns the systemProp.gradle.wrapperUser and systemProp.gradle.wrapperPassword properties the
+// wrapper reads from $GRADLE_USER_HOME/gradle.properties to authenticate distribution downloads, built from the username
+// and password (or token) secrets of a gradle-wrapper binding.
+func WrapperCredentials(binding libcnb.Binding) (map[string]string, error) {
+	username, password, ok, err := BindingCredentials(binding)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return map[string]string{
+		"systemProp.gradle.wrapperUser":     username,
+		"systemProp.gradle.wrapperPassword": password,
+	}, nil
+}
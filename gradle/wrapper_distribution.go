@@ -0,0 +1,78 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/crush"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+// WrapperDistribution expands a bundled Gradle distribution into the wrapper's distribution directory so that gradlew
+// does not have to download it.
+type WrapperDistribution struct {
+	Dependency            libpak.BuildpackDependency
+	DependencyCache       libpak.DependencyCache
+	DistributionDirectory string
+	MarkerFile            string
+	Logger                bard.Logger
+}
+
+func (w WrapperDistribution) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	if ok, err := sherpa.FileExists(w.MarkerFile); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to check for %s\n%w", w.MarkerFile, err)
+	} else if ok {
+		w.Logger.Bodyf("Reusing wrapper distribution in %s", w.DistributionDirectory)
+		return layer, nil
+	}
+
+	w.DependencyCache.Logger = w.Logger
+	artifact, err := w.DependencyCache.Artifact(w.Dependency)
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to get dependency %s\n%w", w.Dependency.ID, err)
+	}
+	defer artifact.Close()
+
+	// remove any partial download left behind by the wrapper
+	if err := os.RemoveAll(w.DistributionDirectory); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to remove %s\n%w", w.DistributionDirectory, err)
+	}
+
+	w.Logger.Bodyf("Expanding Gradle %s to %s", w.Dependency.Version, w.DistributionDirectory)
+	if err := crush.ExtractZip(artifact, w.DistributionDirectory, 0); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to expand Gradle\n%w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(w.MarkerFile), 0755); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to create directory %s\n%w", filepath.Dir(w.MarkerFile), err)
+	}
+	if err := os.WriteFile(w.MarkerFile, []byte{}, 0644); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to write %s\n%w", w.MarkerFile, err)
+	}
+
+	return layer, nil
+}
+
+func (WrapperDistribution) Name() string {
+	return "wrapper-distribution"
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/gradle/v7/gradle"
+)
+
+func testWrapperDistribution(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		ctx                 libcnb.BuildContext
+		distributionDir     string
+		markerFile          string
+		wrapperDistribution gradle.WrapperDistribution
+	)
+
+	it.Before(func() {
+		var err error
+
+		ctx.Layers.Path, err = os.MkdirTemp("", "wrapper-distribution-layers")
+		Expect(err).NotTo(HaveOccurred())
+
+		distributionDir = filepath.Join(ctx.Layers.Path, "home", ".gradle", "wrapper", "dists", "gradle-0.0.0-bin", "hash")
+		markerFile = filepath.Join(distributionDir, "gradle-0.0.0-bin.zip.ok")
+
+		wrapperDistribution = gradle.WrapperDistribution{
+			Dependency: libpak.BuildpackDependency{
+				URI:    "https://localhost/stub-gradle-distribution.zip",
+				SHA256: "5fa754fef54387acdf1ab3107e4ddcaf141e713cd5f946afad4edfbf9461928f",
+			},
+			DependencyCache:       libpak.DependencyCache{CachePath: "testdata"},
+			DistributionDirectory: distributionDir,
+			MarkerFile:            markerFile,
+		}
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(ctx.Layers.Path)).To(Succeed())
+	})
+
+	it("expands the distribution and marks it as installed", func() {
+		layer, err := ctx.Layers.Layer("wrapper-distribution")
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = wrapperDistribution.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(layer.Cache).To(BeFalse())
+		Expect(filepath.Join(distributionDir, "gradle-0.0.0", "fixture-marker")).To(BeARegularFile())
+		Expect(markerFile).To(BeARegularFile())
+	})
+
+	it("replaces a partial download", func() {
+		Expect(os.MkdirAll(distributionDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(distributionDir, "gradle-0.0.0-bin.zip.part"), []byte{}, 0644)).To(Succeed())
+
+		layer, err := ctx.Layers.Layer("wrapper-distribution")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = wrapperDistribution.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filepath.Join(distributionDir, "gradle-0.0.0-bin.zip.part")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(distributionDir, "gradle-0.0.0", "fixture-marker")).To(BeARegularFile())
+	})
+
+	it("reuses an installed distribution", func() {
+		Expect(os.MkdirAll(distributionDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(markerFile, []byte{}, 0644)).To(Succeed())
+
+		layer, err := ctx.Layers.Layer("wrapper-distribution")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = wrapperDistribution.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filepath.Join(distributionDir, "gradle-0.0.0")).NotTo(BeADirectory())
+	})
+}
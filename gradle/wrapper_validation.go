@@ -0,0 +1,133 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+const (
+	WrapperValidationOff    = "off"
+	WrapperValidationStrict = "strict"
+	WrapperValidationWarn   = "warn"
+)
+
+// WrapperValidator verifies an application's gradle-wrapper.jar against a list of known-good checksums.
+type WrapperValidator struct {
+	Checksums map[string]bool
+	Logger    bard.Logger
+	Mode      string
+}
+
+// NewWrapperValidator creates a validator from the gradle-wrapper-checksums buildpack metadata, extended by the
+// gradle-wrapper-checksums secret of a gradle-wrapper binding if present.
+func NewWrapperValidator(mode string, metadata map[string]interface{}, binding libcnb.Binding, logger bard.Logger) (WrapperValidator, error) {
+	switch mode {
+	case WrapperValidationOff, WrapperValidationStrict, WrapperValidationWarn:
+	default:
+		return WrapperValidator{}, fmt.Errorf("invalid wrapper validation mode %s, must be one of %s, %s or %s",
+			mode, WrapperValidationStrict, WrapperValidationWarn, WrapperValidationOff)
+	}
+
+	v := WrapperValidator{Checksums: map[string]bool{}, Logger: logger, Mode: mode}
+
+	switch checksums := metadata["gradle-wrapper-checksums"].(type) {
+	case nil:
+	case []string:
+		for _, c := range checksums {
+			v.Checksums[strings.ToLower(c)] = true
+		}
+	case []interface{}:
+		for _, c := range checksums {
+			s, ok := c.(string)
+			if !ok {
+				return WrapperValidator{}, fmt.Errorf("invalid gradle-wrapper-checksums entry %v", c)
+			}
+			v.Checksums[strings.ToLower(s)] = true
+		}
+	default:
+		return WrapperValidator{}, fmt.Errorf("invalid gradle-wrapper-checksums %v", checksums)
+	}
+
+	if path, ok := binding.SecretFilePath("gradle-wrapper-checksums"); ok {
+		file, err := os.Open(path)
+		if err != nil {
+			return WrapperValidator{}, fmt.Errorf("unable to open %s\n%w", path, err)
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			v.Checksums[strings.ToLower(line)] = true
+		}
+		if err := scanner.Err(); err != nil {
+			return WrapperValidator{}, fmt.Errorf("unable to read %s\n%w", path, err)
+		}
+	}
+
+	return v, nil
+}
+
+// Validate hashes the wrapper JAR at path. An unknown or missing JAR fails in strict mode and is logged in warn mode.
+func (w WrapperValidator) Validate(path string) error {
+	if w.Mode == WrapperValidationOff {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return w.fail(fmt.Sprintf("%s does not exist", path))
+	} else if err != nil {
+		return fmt.Errorf("unable to open %s\n%w", path, err)
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return fmt.Errorf("unable to hash %s\n%w", path, err)
+	}
+	checksum := hex.EncodeToString(hasher.Sum(nil))
+
+	if !w.Checksums[checksum] {
+		return w.fail(fmt.Sprintf("%s has unknown checksum %s", path, checksum))
+	}
+
+	w.Logger.Bodyf("Validated %s with checksum %s", path, checksum)
+	return nil
+}
+
+func (w WrapperValidator) fail(reason string) error {
+	if w.Mode == WrapperValidationStrict {
+		return fmt.Errorf("gradle wrapper validation failed: %s", reason)
+	}
+
+	w.Logger.Bodyf("WARNING: gradle wrapper validation failed: %s", reason)
+	return nil
+}
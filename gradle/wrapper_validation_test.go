@@ -0,0 +1,147 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gradle_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/gradle/v7/gradle"
+)
+
+func testWrapperValidation(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		buffer   *bytes.Buffer
+		dir      string
+		jarPath  string
+		metadata map[string]interface{}
+	)
+
+	// sha256 of the string "wrapper-jar-content"
+	const checksum = "c853178b55d45717b96369e3beebc288c7461c24d6dd019af358b5fc183321dd"
+
+	it.Before(func() {
+		var err error
+
+		dir, err = os.MkdirTemp("", "wrapper-validation")
+		Expect(err).NotTo(HaveOccurred())
+
+		jarPath = filepath.Join(dir, "gradle-wrapper.jar")
+		Expect(os.WriteFile(jarPath, []byte("wrapper-jar-content"), 0644)).To(Succeed())
+
+		buffer = &bytes.Buffer{}
+		metadata = map[string]interface{}{"gradle-wrapper-checksums": []interface{}{checksum}}
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	it("fails with an invalid mode", func() {
+		_, err := gradle.NewWrapperValidator("sometimes", metadata, libcnb.Binding{}, bard.NewLogger(buffer))
+		Expect(err).To(MatchError(ContainSubstring("invalid wrapper validation mode sometimes")))
+	})
+
+	it("accepts a known checksum", func() {
+		v, err := gradle.NewWrapperValidator("strict", metadata, libcnb.Binding{}, bard.NewLogger(buffer))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(v.Validate(jarPath)).To(Succeed())
+		Expect(buffer.String()).To(ContainSubstring("Validated"))
+	})
+
+	it("rejects an unknown checksum in strict mode", func() {
+		v, err := gradle.NewWrapperValidator("strict", map[string]interface{}{}, libcnb.Binding{}, bard.NewLogger(buffer))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(v.Validate(jarPath)).To(MatchError(ContainSubstring("has unknown checksum " + checksum)))
+	})
+
+	it("rejects a missing jar in strict mode", func() {
+		v, err := gradle.NewWrapperValidator("strict", metadata, libcnb.Binding{}, bard.NewLogger(buffer))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(v.Validate(filepath.Join(dir, "missing.jar"))).To(MatchError(ContainSubstring("does not exist")))
+	})
+
+	it("warns about an unknown checksum in warn mode", func() {
+		v, err := gradle.NewWrapperValidator("warn", map[string]interface{}{}, libcnb.Binding{}, bard.NewLogger(buffer))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(v.Validate(jarPath)).To(Succeed())
+		Expect(buffer.String()).To(ContainSubstring("WARNING: gradle wrapper validation failed"))
+	})
+
+	it("does nothing when off", func() {
+		v, err := gradle.NewWrapperValidator("off", map[string]interface{}{}, libcnb.Binding{}, bard.NewLogger(buffer))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(v.Validate(filepath.Join(dir, "missing.jar"))).To(Succeed())
+		Expect(buffer.String()).To(BeEmpty())
+	})
+
+	it("accepts checksums from a binding", func() {
+		binding := libcnb.Binding{
+			Name:   "some-wrapper",
+			Type:   "gradle-wrapper",
+			Path:   filepath.Join(dir, "binding"),
+			Secret: map[string]string{"gradle-wrapper-checksums": "# known wrappers\n\n" + checksum + "\n"},
+		}
+		path, _ := binding.SecretFilePath("gradle-wrapper-checksums")
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(binding.Secret["gradle-wrapper-checksums"]), 0644)).To(Succeed())
+
+		v, err := gradle.NewWrapperValidator("strict", map[string]interface{}{}, binding, bard.NewLogger(buffer))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(v.Validate(jarPath)).To(Succeed())
+	})
+
+	it("ships known checksums of released wrapper jars in the buildpack metadata", func() {
+		var buildpack struct {
+			Metadata map[string]interface{} `toml:"metadata"`
+		}
+		_, err := toml.DecodeFile(filepath.Join("..", "buildpack.toml"), &buildpack)
+		Expect(err).NotTo(HaveOccurred())
+
+		v, err := gradle.NewWrapperValidator(gradle.WrapperValidationStrict, buildpack.Metadata, libcnb.Binding{}, bard.NewLogger(buffer))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(v.Checksums).NotTo(BeEmpty())
+		for checksum := range v.Checksums {
+			Expect(checksum).To(MatchRegexp(`^[0-9a-f]{64}$`))
+		}
+		Expect(v.Checksums).To(HaveLen(len(buildpack.Metadata["gradle-wrapper-checksums"].([]interface{}))))
+
+		// gradle-wrapper.jar generated by Gradle 8.7
+		Expect(v.Checksums).To(HaveKey("cb0da6751c2b753a16ac168bb354870ebb1e162e9083f116729cec9c781156b8"))
+
+		// testdata/gradle-wrapper.jar is the gradle-wrapper.jar generated by Gradle 9.6.1
+		Expect(v.Validate(filepath.Join("testdata", "gradle-wrapper.jar"))).To(Succeed())
+		Expect(buffer.String()).To(ContainSubstring("497c8c2a7e5031f6aa847f88104aa80a93532ec32ee17bdb8d1d2f67a194a9c7"))
+	})
+}
@@ -0,0 +1,31 @@
+#!/usr/bin/env bash
+set -euo pipefail
+
+# Replaces the gradle-wrapper-checksums of buildpack.toml with the published SHA-256 checksums of the gradle-wrapper.jar
+# of every Gradle release, release candidate and milestone listed by services.gradle.org.
+
+BUILDPACK_TOML="${1:-buildpack.toml}"
+
+CHECKSUMS=$(
+  curl --fail --silent --show-error --location https://services.gradle.org/versions/all |
+    jq -r '.[] | select(.snapshot == false and .nightly == false and .releaseNightly == false) | .wrapperChecksumUrl // empty' |
+    while read -r url; do
+      curl --fail --silent --show-error --location "$url"
+      echo
+    done |
+    tr -d '[:blank:]' |
+    grep -E '^[0-9a-f]{64}$' |
+    sort -u
+)
+
+if [ -z "$CHECKSUMS" ]; then
+  echo "No wrapper checksums found"
+  exit 1
+fi
+
+ARRAY=$(printf '    "%s",\n' $CHECKSUMS)
+ARRAY="gradle-wrapper-checksums = [
+${ARRAY}
+  ]" perl -0pi -e 's/gradle-wrapper-checksums = \[.*?\]/$ENV{ARRAY}/s' "$BUILDPACK_TOML"
+
+echo "Updated $BUILDPACK_TOML with $(echo "$CHECKSUMS" | wc -l | tr -d ' ') wrapper checksums"